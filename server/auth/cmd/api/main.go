@@ -3,10 +3,15 @@ package main
 import (
 	"authentication/internal/config"
 	"authentication/internal/db"
+	"authentication/internal/handler"
 	"authentication/internal/model"
 	"authentication/internal/router"
+	"authentication/internal/token"
+	"database/sql"
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -26,14 +31,55 @@ func main() {
 
 	model.SetDB(dbpool)
 
+	tokenManager, err := token.NewManager(token.Config{
+		PrivateKeyPEM:  config.GetEnv("JWT_PRIVATE_KEY", ""),
+		PrivateKeyFile: config.GetEnv("JWT_PRIVATE_KEY_FILE", ""),
+		Issuer:         config.GetEnv("JWT_ISSUER", "authentication-service"),
+		Audience:       config.GetEnv("JWT_AUDIENCE", "go-microservices"),
+		AccessTTL:      config.GetDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTTL:     config.GetDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize token manager: %v", err)
+	}
+
+	handler.SetTokenManager(tokenManager)
+
+	if email := config.GetEnv("ADMIN_EMAIL", ""); email != "" {
+		bootstrapAdmin(email)
+	}
+
 	log.Printf("Auth Service starting at: http://localhost:%s", AUTH_PORT)
 
 	srv := &http.Server{
 		Addr:    ":" + AUTH_PORT,
-		Handler: router.Routes(),
+		Handler: router.Routes(tokenManager, config.GetEnv("SERVICE_API_KEY", "")),
 	}
 
 	if err := srv.ListenAndServe(); err != nil {
 		log.Fatalf("Failed to start the server: %v", err)
 	}
 }
+
+// bootstrapAdmin grants the admin role to the account registered with
+// email. Migrations give every user only the user role, and assigning
+// roles requires roles:manage, so this is how the first administrator is
+// made: register the account, then start the service with ADMIN_EMAIL set.
+// The account must already exist, so nobody can claim the address by
+// registering it later.
+func bootstrapAdmin(email string) {
+	var u model.User
+	user, err := u.GetByEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("ADMIN_EMAIL: no account registered as %s yet; register it and restart to grant admin", email)
+		return
+	}
+	if err != nil {
+		log.Fatalf("ADMIN_EMAIL: failed to look up %s: %v", email, err)
+	}
+
+	if err := user.AssignRole("admin"); err != nil {
+		log.Fatalf("ADMIN_EMAIL: failed to grant admin to %s: %v", email, err)
+	}
+	log.Printf("ADMIN_EMAIL: %s has the admin role", email)
+}
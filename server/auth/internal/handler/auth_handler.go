@@ -2,13 +2,23 @@ package handler
 
 import (
 	"authentication/internal/model"
+	"authentication/internal/token"
 	"authentication/utils"
 	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"time"
 )
 
+const defaultRole = "user"
+
+var tokens *token.Manager
+
+func SetTokenManager(m *token.Manager) {
+	tokens = m
+}
+
 func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	var signUpPayload struct {
 		Email     string `json:"email"`
@@ -23,6 +33,17 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if signUpPayload.Email == "" || signUpPayload.Password == "" {
+		utils.ErrorJSON(w, errors.New("email and password are required"), http.StatusBadRequest)
+		return
+	}
+
+	var u model.User
+	if _, err := u.GetByEmail(signUpPayload.Email); err == nil {
+		utils.ErrorJSON(w, errors.New("a user with this email already exists"), http.StatusConflict)
+		return
+	}
+
 	newUser := model.User{
 		Email:     signUpPayload.Email,
 		Password:  signUpPayload.Password,
@@ -37,6 +58,11 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	newUser.ID = id
+	if err := newUser.AssignRole(defaultRole); err != nil {
+		log.Printf("Failed to assign default role to user %d: %v", id, err)
+	}
+
 	utils.WriteJSON(w, http.StatusCreated, map[string]any{
 		"message": "user registered successfully",
 		"user_id": id,
@@ -57,23 +83,187 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var u model.User
-	
+
 	user, err := u.GetByEmail(payload.Email)
 
 	if err != nil {
-		utils.ErrorJSON(w, err, http.StatusUnauthorized)
+		utils.ErrorJSON(w, errors.New("invalid credentials"), http.StatusUnauthorized)
 		return
 	}
 
 	match, err := user.PasswordMatches(payload.Password)
 	if err != nil || !match {
-		utils.ErrorJSON(w, err, http.StatusUnauthorized)
+		utils.ErrorJSON(w, errors.New("invalid credentials"), http.StatusUnauthorized)
+		return
+	}
+
+	if !user.Active {
+		utils.ErrorJSON(w, errors.New("user account is inactive"), http.StatusUnauthorized)
+		return
+	}
+
+	session, err := newSession(user)
+	if err != nil {
+		log.Printf("Failed to issue tokens for user %d: %v", user.ID, err)
+		utils.ErrorJSON(w, errors.New("failed to issue tokens"), http.StatusInternalServerError)
+		return
+	}
+
+	var rt model.RefreshToken
+	if _, err := rt.Insert(session.refreshToken); err != nil {
+		log.Printf("Failed to store refresh token for user %d: %v", user.ID, err)
+		utils.ErrorJSON(w, errors.New("failed to issue tokens"), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, session.response(user, "login successful"))
+}
+
+func RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	if err := utils.ReadJSON(w, r, &payload); err != nil || payload.RefreshToken == "" {
+		utils.ErrorJSON(w, errors.New("refresh_token is required"), http.StatusBadRequest)
+		return
+	}
+
+	var rt model.RefreshToken
+
+	current, err := rt.GetByHash(token.HashRefreshToken(payload.RefreshToken))
+	if err != nil {
+		utils.ErrorJSON(w, errors.New("invalid refresh token"), http.StatusUnauthorized)
 		return
 	}
 
+	if current.Revoked() {
+		// A revoked token being replayed means it leaked; end every session
+		// of the user rather than guessing which copy is legitimate.
+		if err := rt.RevokeAllForUser(current.UserID); err != nil {
+			log.Printf("Failed to revoke sessions for user %d: %v", current.UserID, err)
+		}
+		log.Printf("Refresh token reuse detected for user %d", current.UserID)
+		utils.ErrorJSON(w, errors.New("invalid refresh token"), http.StatusUnauthorized)
+		return
+	}
+
+	if current.Expired() {
+		utils.ErrorJSON(w, errors.New("refresh token has expired"), http.StatusUnauthorized)
+		return
+	}
+
+	var u model.User
+
+	user, err := u.GetOne(current.UserID)
+	if err != nil || !user.Active {
+		utils.ErrorJSON(w, errors.New("invalid refresh token"), http.StatusUnauthorized)
+		return
+	}
+
+	session, err := newSession(user)
+	if err != nil {
+		log.Printf("Failed to issue tokens for user %d: %v", user.ID, err)
+		utils.ErrorJSON(w, errors.New("failed to issue tokens"), http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := current.Rotate(session.refreshToken); err != nil {
+		if errors.Is(err, model.ErrTokenAlreadyUsed) {
+			utils.ErrorJSON(w, errors.New("invalid refresh token"), http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Failed to rotate refresh token for user %d: %v", user.ID, err)
+		utils.ErrorJSON(w, errors.New("failed to issue tokens"), http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, session.response(user, "token refreshed"))
+}
+
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+		All          bool   `json:"all,omitempty"`
+	}
+
+	if err := utils.ReadJSON(w, r, &payload); err != nil || payload.RefreshToken == "" {
+		utils.ErrorJSON(w, errors.New("refresh_token is required"), http.StatusBadRequest)
+		return
+	}
+
+	var rt model.RefreshToken
+
+	current, err := rt.GetByHash(token.HashRefreshToken(payload.RefreshToken))
+	if err == nil {
+		if payload.All {
+			err = rt.RevokeAllForUser(current.UserID)
+		} else {
+			err = current.Revoke()
+		}
+
+		if err != nil {
+			utils.ErrorJSON(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Unknown tokens are treated as already logged out so the call stays idempotent.
 	utils.WriteJSON(w, http.StatusOK, map[string]any{
 		"success": true,
-		"message": "login successful",
-		"user_id": user.ID,
+		"message": "logged out",
 	})
-}
\ No newline at end of file
+}
+
+type session struct {
+	accessToken  string
+	plainRefresh string
+	refreshToken model.RefreshToken
+}
+
+func newSession(user *model.User) (*session, error) {
+	roles, err := user.Roles()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := tokens.IssueAccessToken(user.ID, user.Email, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, hash, expiresAt, err := tokens.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &session{
+		accessToken:  accessToken,
+		plainRefresh: plain,
+		refreshToken: model.RefreshToken{
+			UserID:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: expiresAt,
+		},
+	}, nil
+}
+
+func (s *session) response(user *model.User, message string) map[string]any {
+	return map[string]any{
+		"success":            true,
+		"message":            message,
+		"user_id":            user.ID,
+		"user":               user,
+		"access_token":       s.accessToken,
+		"refresh_token":      s.plainRefresh,
+		"token_type":         "Bearer",
+		"expires_in":         int64(tokens.AccessTTL() / time.Second),
+		"refresh_expires_at": s.refreshToken.ExpiresAt,
+	}
+}
+
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, tokens.JWKS(), http.Header{
+		"Cache-Control": []string{"public, max-age=300"},
+	})
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"authentication/internal/model"
+	"authentication/internal/token"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupRefresh wires the handlers to a fake database holding one active
+// user and returns a refresh token issued to them.
+func setupRefresh(t *testing.T) (*fakeDB, string) {
+	t.Helper()
+
+	manager, err := token.NewManager(token.Config{
+		Issuer:     "authentication-service",
+		Audience:   "go-microservices",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTokenManager(manager)
+
+	fake := &fakeDB{
+		users:  map[int64]fakeUser{1: {email: "ada@example.com", active: true, roles: []string{"user"}}},
+		tokens: map[int64]fakeToken{},
+	}
+	conn, err := fake.open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	model.SetDB(conn)
+
+	plain, hash, expiresAt, err := manager.NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rt model.RefreshToken
+	if _, err := rt.Insert(model.RefreshToken{UserID: 1, TokenHash: hash, ExpiresAt: expiresAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	return fake, plain
+}
+
+// refresh posts the refresh token and returns the status and, on success,
+// the refresh token that replaced it.
+func refresh(t *testing.T, plain string) (int, string) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"refresh_token": plain})
+	rec := httptest.NewRecorder()
+	RefreshTokenHandler(rec, httptest.NewRequest(http.MethodPost, "/refresh", strings.NewReader(string(body))))
+
+	var response struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.AccessToken == "" {
+			t.Error("refresh returned no access token")
+		}
+	}
+	return rec.Code, response.RefreshToken
+}
+
+func TestRefreshRotates(t *testing.T) {
+	fake, first := setupRefresh(t)
+
+	status, second := refresh(t, first)
+	if status != http.StatusOK || second == "" || second == first {
+		t.Fatalf("refresh = %d with token %q, want 200 and a new token", status, second)
+	}
+
+	old, next := fake.tokens[1], fake.tokens[2]
+	if old.revokedAt == nil || old.replacedBy != int64(2) {
+		t.Errorf("rotated token is revoked=%v replaced_by=%v, want revoked and replaced by 2", old.revokedAt, old.replacedBy)
+	}
+	if next.revokedAt != nil || next.hash != token.HashRefreshToken(second) {
+		t.Error("replacement token was not stored by its hash")
+	}
+
+	if status, third := refresh(t, second); status != http.StatusOK || third == "" {
+		t.Errorf("refreshing with the replacement = %d, want 200", status)
+	}
+}
+
+func TestRefreshReuseRevokesEverySession(t *testing.T) {
+	fake, first := setupRefresh(t)
+
+	_, second := refresh(t, first)
+	if second == "" {
+		t.Fatal("first refresh failed")
+	}
+
+	// Replaying the rotated token means it leaked.
+	if status, _ := refresh(t, first); status != http.StatusUnauthorized {
+		t.Fatalf("replaying a rotated token = %d, want 401", status)
+	}
+	for id, stored := range fake.tokens {
+		if stored.revokedAt == nil {
+			t.Errorf("token %d survived reuse detection", id)
+		}
+	}
+	if status, _ := refresh(t, second); status != http.StatusUnauthorized {
+		t.Errorf("the legitimate token still refreshes after reuse = %d, want 401", status)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(fake *fakeDB, plain string) string
+	}{
+		{"unknown token", func(*fakeDB, string) string { return "not-issued" }},
+		{"empty token", func(*fakeDB, string) string { return "" }},
+		{"expired token", func(fake *fakeDB, plain string) string {
+			stored := fake.tokens[1]
+			stored.expiresAt = time.Now().Add(-time.Minute)
+			fake.tokens[1] = stored
+			return plain
+		}},
+		{"inactive user", func(fake *fakeDB, plain string) string {
+			user := fake.users[1]
+			user.active = false
+			fake.users[1] = user
+			return plain
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake, plain := setupRefresh(t)
+			status, _ := refresh(t, c.setup(fake, plain))
+			if status != http.StatusUnauthorized && status != http.StatusBadRequest {
+				t.Errorf("refresh = %d, want it refused", status)
+			}
+			if fake.tokens[1].replacedBy != nil {
+				t.Error("a refused refresh rotated the token")
+			}
+		})
+	}
+}
+
+func TestRotateOnce(t *testing.T) {
+	fake, first := setupRefresh(t)
+
+	var rt model.RefreshToken
+	current, err := rt.GetByHash(token.HashRefreshToken(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := current.Rotate(model.RefreshToken{UserID: 1, TokenHash: "next-1", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("first Rotate: %v", err)
+	}
+
+	// A second exchange of the same token, as by a concurrent request that
+	// read it before the first committed, must fail and store nothing.
+	_, err = current.Rotate(model.RefreshToken{UserID: 1, TokenHash: "next-2", ExpiresAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, model.ErrTokenAlreadyUsed) {
+		t.Fatalf("second Rotate = %v, want ErrTokenAlreadyUsed", err)
+	}
+	for _, stored := range fake.tokens {
+		if stored.hash == "next-2" {
+			t.Error("the losing Rotate kept its replacement token")
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeDB stands in for Postgres. It answers only the statements the user
+// and refresh token models issue, matched on their normalised text.
+type fakeDB struct {
+	mu     sync.Mutex
+	users  map[int64]fakeUser
+	tokens map[int64]fakeToken
+	nextID int64
+}
+
+type fakeUser struct {
+	email  string
+	active bool
+	roles  []string
+}
+
+type fakeToken struct {
+	userID     int64
+	hash       string
+	expiresAt  time.Time
+	revokedAt  any
+	replacedBy any
+	createdAt  time.Time
+}
+
+var fakeDriverSeq int
+
+// open registers the fake under a fresh driver name and opens it.
+func (f *fakeDB) open() (*sql.DB, error) {
+	fakeDriverSeq++
+	name := fmt.Sprintf("fakedb-%d", fakeDriverSeq)
+	sql.Register(name, fakeDriver{f})
+	return sql.Open(name, "")
+}
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{db: d.db}, nil }
+
+type fakeConn struct {
+	db       *fakeDB
+	snapshot map[int64]fakeToken
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+// Begin snapshots the tokens so a rollback undoes the transaction.
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	c.snapshot = maps.Clone(c.db.tokens)
+	c.db.mu.Unlock()
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.snapshot = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	if c.snapshot != nil {
+		c.db.mu.Lock()
+		c.db.tokens = c.snapshot
+		c.db.mu.Unlock()
+		c.snapshot = nil
+	}
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var affected int64
+	revoke := func(id int64, replacedBy any) {
+		token := db.tokens[id]
+		token.revokedAt = args[0]
+		token.replacedBy = replacedBy
+		db.tokens[id] = token
+		affected++
+	}
+
+	switch s.query {
+	case "update refresh_tokens set revoked_at = $1, replaced_by = $2 where id = $3 and revoked_at is null":
+		if token, ok := db.tokens[args[2].(int64)]; ok && token.revokedAt == nil {
+			revoke(args[2].(int64), args[1])
+		}
+	case "update refresh_tokens set revoked_at = $1 where id = $2 and revoked_at is null":
+		if token, ok := db.tokens[args[1].(int64)]; ok && token.revokedAt == nil {
+			revoke(args[1].(int64), token.replacedBy)
+		}
+	case "update refresh_tokens set revoked_at = $1 where user_id = $2 and revoked_at is null":
+		for id, token := range db.tokens {
+			if token.userID == args[1].(int64) && token.revokedAt == nil {
+				revoke(id, token.replacedBy)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("fakedb: unexpected exec %q", s.query)
+	}
+
+	return driver.RowsAffected(affected), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	switch {
+	case s.query == "select id, user_id, token_hash, expires_at, revoked_at, replaced_by, created_at from refresh_tokens where token_hash = $1":
+		for id, token := range db.tokens {
+			if token.hash == args[0].(string) {
+				return rows(driver.Value(id), token.userID, token.hash, token.expiresAt, token.revokedAt, token.replacedBy, token.createdAt), nil
+			}
+		}
+		return rows(), nil
+
+	case strings.HasPrefix(s.query, "insert into refresh_tokens "):
+		db.nextID++
+		db.tokens[db.nextID] = fakeToken{
+			userID:    args[0].(int64),
+			hash:      args[1].(string),
+			expiresAt: args[2].(time.Time),
+			createdAt: args[3].(time.Time),
+		}
+		return rows(driver.Value(db.nextID)), nil
+
+	case s.query == "select id, email, first_name, last_name, password, user_active, created_at, updated_at from users where id = $1":
+		user, ok := db.users[args[0].(int64)]
+		if !ok {
+			return rows(), nil
+		}
+		now := time.Now()
+		return rows(args[0], user.email, "", "", "", user.active, now, now), nil
+
+	case strings.HasPrefix(s.query, "select r.name from roles r "):
+		var values []driver.Value
+		for _, role := range db.users[args[0].(int64)].roles {
+			values = append(values, role)
+		}
+		return &fakeRows{columns: 1, values: values}, nil
+	}
+
+	return nil, fmt.Errorf("fakedb: unexpected query %q", s.query)
+}
+
+// rows returns a single row with the given values, or none.
+func rows(values ...driver.Value) *fakeRows {
+	return &fakeRows{columns: len(values), values: values}
+}
+
+type fakeRows struct {
+	columns int
+	values  []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return make([]string, max(r.columns, 1)) }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	if len(dest) > len(r.values) {
+		return errors.New("fakedb: short row")
+	}
+	n := copy(dest, r.values)
+	r.values = r.values[n:]
+	return nil
+}
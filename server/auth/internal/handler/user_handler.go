@@ -1 +1,183 @@
-package handler
\ No newline at end of file
+package handler
+
+import (
+	"authentication/internal/model"
+	"authentication/utils"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func ListRolesHandler(w http.ResponseWriter, r *http.Request) {
+	var role model.Role
+
+	roles, err := role.GetAll()
+	if err != nil {
+		utils.ErrorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"roles":   roles,
+	})
+}
+
+func GetUserRolesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := userFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := user.Roles()
+	if err != nil {
+		utils.ErrorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	permissions, err := user.Permissions()
+	if err != nil {
+		utils.ErrorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"success":     true,
+		"user_id":     user.ID,
+		"roles":       roles,
+		"permissions": permissions,
+	})
+}
+
+func AssignRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Role string `json:"role"`
+	}
+
+	if err := utils.ReadJSON(w, r, &payload); err != nil || payload.Role == "" {
+		utils.ErrorJSON(w, errors.New("role is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := userFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := user.AssignRole(payload.Role); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	log.Printf("Role %s assigned to user %d", payload.Role, user.ID)
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "role assigned",
+	})
+}
+
+func RevokeRoleHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := userFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	role := chi.URLParam(r, "role")
+
+	if err := user.RevokeRole(role); err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	log.Printf("Role %s revoked from user %d", role, user.ID)
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"success": true,
+		"message": "role revoked",
+	})
+}
+
+// AuthorizeHandler answers permission checks from other services. A denied
+// permission is a normal answer, not an error, so it is reported with 200.
+func AuthorizeHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID     int    `json:"user_id"`
+		Permission string `json:"permission"`
+	}
+
+	if err := utils.ReadJSON(w, r, &payload); err != nil {
+		utils.ErrorJSON(w, errors.New("invalid request payload"), http.StatusBadRequest)
+		return
+	}
+
+	if payload.UserID == 0 || payload.Permission == "" {
+		utils.ErrorJSON(w, errors.New("user_id and permission are required"), http.StatusBadRequest)
+		return
+	}
+
+	var u model.User
+
+	user, err := u.GetOne(payload.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.WriteJSON(w, http.StatusOK, map[string]any{
+				"allowed":    false,
+				"user_id":    payload.UserID,
+				"permission": payload.Permission,
+			})
+			return
+		}
+		utils.ErrorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	allowed := false
+	if user.Active {
+		allowed, err = user.HasPermission(payload.Permission)
+		if err != nil {
+			utils.ErrorJSON(w, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]any{
+		"allowed":    allowed,
+		"user_id":    user.ID,
+		"permission": payload.Permission,
+	})
+}
+
+func userFromPath(w http.ResponseWriter, r *http.Request) (*model.User, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.ErrorJSON(w, errors.New("invalid user id"), http.StatusBadRequest)
+		return nil, false
+	}
+
+	var u model.User
+
+	user, err := u.GetOne(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.ErrorJSON(w, errors.New("user not found"), http.StatusNotFound)
+			return nil, false
+		}
+		utils.ErrorJSON(w, err, http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return user, true
+}
+
+func writeRoleError(w http.ResponseWriter, err error) {
+	if errors.Is(err, model.ErrRoleNotFound) {
+		utils.ErrorJSON(w, err, http.StatusNotFound)
+		return
+	}
+	utils.ErrorJSON(w, err, http.StatusInternalServerError)
+}
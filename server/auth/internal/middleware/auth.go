@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"authentication/internal/model"
+	"authentication/internal/token"
+	"authentication/utils"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type contextKey struct{}
+
+// RequireAuth rejects requests without a valid access token and stores the
+// token claims in the request context.
+func RequireAuth(tokens *token.Manager) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !found || tokenString == "" {
+				utils.ErrorJSON(w, errors.New("authorization token is required"), http.StatusUnauthorized)
+				return
+			}
+
+			claims, err := tokens.ParseAccessToken(tokenString)
+			if err != nil {
+				utils.ErrorJSON(w, err, http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, claims)))
+		})
+	}
+}
+
+// RequirePermission must run after RequireAuth. Permissions are read from
+// the database rather than the token so revoking a role takes effect at once.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				utils.ErrorJSON(w, errors.New("authorization token is required"), http.StatusUnauthorized)
+				return
+			}
+
+			userID, err := strconv.Atoi(claims.Subject)
+			if err != nil {
+				utils.ErrorJSON(w, token.ErrInvalidToken, http.StatusUnauthorized)
+				return
+			}
+
+			user := model.User{ID: userID}
+			allowed, err := user.HasPermission(permission)
+			if err != nil {
+				log.Printf("Permission check failed for user %d: %v", userID, err)
+				utils.ErrorJSON(w, errors.New("failed to check permissions"), http.StatusInternalServerError)
+				return
+			}
+
+			if !allowed {
+				utils.ErrorJSON(w, errors.New("permission denied"), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireServiceKey guards service-to-service endpoints with a shared key.
+// Without a key the endpoints refuse every caller rather than run open.
+func RequireServiceKey(key string) func(http.Handler) http.Handler {
+	if key == "" {
+		log.Println("SERVICE_API_KEY is not set, service endpoints will refuse every request")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if key == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+				utils.ErrorJSON(w, errors.New("invalid service key"), http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ClaimsFromContext(ctx context.Context) (*token.Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*token.Claims)
+	return claims, ok
+}
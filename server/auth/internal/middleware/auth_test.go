@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireServiceKey(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cases := []struct {
+		name       string
+		configured string
+		header     string
+		want       int
+	}{
+		{"matching key", "s3cret", "Bearer s3cret", http.StatusOK},
+		{"wrong key", "s3cret", "Bearer guess", http.StatusUnauthorized},
+		{"missing key", "s3cret", "", http.StatusUnauthorized},
+		{"unconfigured, no header", "", "", http.StatusUnauthorized},
+		{"unconfigured, empty bearer", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/authorize", nil)
+			if c.header != "" {
+				r.Header.Set("Authorization", c.header)
+			}
+			rec := httptest.NewRecorder()
+
+			RequireServiceKey(c.configured)(ok).ServeHTTP(rec, r)
+			if rec.Code != c.want {
+				t.Errorf("status = %d, want %d", rec.Code, c.want)
+			}
+		})
+	}
+}
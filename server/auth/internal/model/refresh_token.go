@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrTokenAlreadyUsed = errors.New("refresh token has already been used")
+
+type RefreshToken struct {
+	ID         int           `json:"id"`
+	UserID     int           `json:"user_id"`
+	TokenHash  string        `json:"-"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	RevokedAt  sql.NullTime  `json:"-"`
+	ReplacedBy sql.NullInt64 `json:"-"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func (t *RefreshToken) Revoked() bool {
+	return t.RevokedAt.Valid
+}
+
+func (t *RefreshToken) Expired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+func (t *RefreshToken) GetByHash(hash string) (*RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	query := `select id, user_id, token_hash, expires_at, revoked_at, replaced_by, created_at
+	from refresh_tokens where token_hash = $1`
+
+	var token RefreshToken
+	row := db.QueryRowContext(ctx, query, hash)
+
+	err := row.Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.RevokedAt, &token.ReplacedBy, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (t *RefreshToken) Insert(token RefreshToken) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var newID int
+	stmt := `insert into refresh_tokens (user_id, token_hash, expires_at, created_at)
+		values ($1, $2, $3, $4) returning id`
+
+	err := db.QueryRowContext(ctx, stmt, token.UserID, token.TokenHash, token.ExpiresAt, time.Now()).Scan(&newID)
+	if err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+// Rotate revokes t and stores next as its replacement in a single
+// transaction. It fails with ErrTokenAlreadyUsed if t was revoked
+// concurrently, so a refresh token can only ever be exchanged once.
+func (t *RefreshToken) Rotate(next RefreshToken) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var newID int
+	stmt := `insert into refresh_tokens (user_id, token_hash, expires_at, created_at)
+		values ($1, $2, $3, $4) returning id`
+
+	err = tx.QueryRowContext(ctx, stmt, next.UserID, next.TokenHash, next.ExpiresAt, time.Now()).Scan(&newID)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt = `update refresh_tokens set revoked_at = $1, replaced_by = $2 where id = $3 and revoked_at is null`
+
+	result, err := tx.ExecContext(ctx, stmt, time.Now(), newID, t.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, ErrTokenAlreadyUsed
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return newID, nil
+}
+
+func (t *RefreshToken) Revoke() error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	stmt := `update refresh_tokens set revoked_at = $1 where id = $2 and revoked_at is null`
+
+	_, err := db.ExecContext(ctx, stmt, time.Now(), t.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (t *RefreshToken) RevokeAllForUser(userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	stmt := `update refresh_tokens set revoked_at = $1 where user_id = $2 and revoked_at is null`
+
+	_, err := db.ExecContext(ctx, stmt, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
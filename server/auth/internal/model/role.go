@@ -0,0 +1,164 @@
+package model
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+
+type Role struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+func (r *Role) GetAll() ([]*Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	query := `select r.id, r.name, coalesce(r.description, ''), coalesce(array_agg(p.name order by p.name) filter (where p.name is not null), '{}')
+	from roles r
+	left join role_permissions rp on rp.role_id = r.id
+	left join permissions p on p.id = rp.permission_id
+	group by r.id order by r.name`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*Role
+
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, pq.Array(&role.Permissions)); err != nil {
+			return nil, err
+		}
+		roles = append(roles, &role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (u *User) Roles() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	query := `select r.name from roles r
+	join user_roles ur on ur.role_id = r.id
+	where ur.user_id = $1 order by r.name`
+
+	return queryNames(ctx, query, u.ID)
+}
+
+func (u *User) Permissions() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	query := `select distinct p.name from permissions p
+	join role_permissions rp on rp.permission_id = p.id
+	join user_roles ur on ur.role_id = rp.role_id
+	where ur.user_id = $1 order by p.name`
+
+	return queryNames(ctx, query, u.ID)
+}
+
+func (u *User) HasPermission(permission string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	query := `select exists (
+		select 1 from user_roles ur
+		join role_permissions rp on rp.role_id = ur.role_id
+		join permissions p on p.id = rp.permission_id
+		where ur.user_id = $1 and p.name = $2
+	)`
+
+	var allowed bool
+	if err := db.QueryRowContext(ctx, query, u.ID, permission).Scan(&allowed); err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+func (u *User) AssignRole(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	stmt := `insert into user_roles (user_id, role_id)
+	select $1, id from roles where name = $2
+	on conflict do nothing`
+
+	result, err := db.ExecContext(ctx, stmt, u.ID, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		// Either the role does not exist or the user already has it.
+		exists, err := roleExists(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRoleNotFound
+		}
+	}
+
+	return nil
+}
+
+func (u *User) RevokeRole(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	exists, err := roleExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRoleNotFound
+	}
+
+	stmt := `delete from user_roles where user_id = $1 and role_id = (select id from roles where name = $2)`
+
+	_, err = db.ExecContext(ctx, stmt, u.ID, name)
+	return err
+}
+
+func roleExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, `select exists (select 1 from roles where name = $1)`, name).Scan(&exists)
+	return exists, err
+}
+
+func queryNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"authentication/internal/handler"
+	"authentication/internal/middleware"
+	"authentication/internal/token"
 	"authentication/utils"
 	"net/http"
 
@@ -15,7 +17,7 @@ type JsonResponse struct {
 	Data    any    `json:"data,omitempty"`
 }
 
-func Routes() http.Handler {
+func Routes(tokens *token.Manager, serviceKey string) http.Handler {
 	mux := chi.NewRouter()
 
 	mux.Use(cors.Handler(cors.Options{
@@ -40,6 +42,21 @@ func Routes() http.Handler {
 
 	mux.Post("/register", handler.RegisterHandler)
 	mux.Post("/login", handler.LoginHandler)
+	mux.Post("/token/refresh", handler.RefreshTokenHandler)
+	mux.Post("/logout", handler.LogoutHandler)
+	mux.Get("/.well-known/jwks.json", handler.JWKSHandler)
+
+	mux.With(middleware.RequireServiceKey(serviceKey)).Post("/authorize", handler.AuthorizeHandler)
+
+	mux.Group(func(admin chi.Router) {
+		admin.Use(middleware.RequireAuth(tokens))
+		admin.Use(middleware.RequirePermission("roles:manage"))
+
+		admin.Get("/roles", handler.ListRolesHandler)
+		admin.Get("/users/{id}/roles", handler.GetUserRolesHandler)
+		admin.Post("/users/{id}/roles", handler.AssignRoleHandler)
+		admin.Delete("/users/{id}/roles/{role}", handler.RevokeRoleHandler)
+	})
 
 	return mux
 }
@@ -0,0 +1,199 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+type Config struct {
+	PrivateKeyPEM  string
+	PrivateKeyFile string
+	Issuer         string
+	Audience       string
+	AccessTTL      time.Duration
+	RefreshTTL     time.Duration
+}
+
+type Claims struct {
+	Email string   `json:"email"`
+	Roles []string `json:"roles,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Manager signs access tokens with an RSA key and mints the opaque
+// refresh tokens that are persisted (hashed) by the model package.
+type Manager struct {
+	privateKey *rsa.PrivateKey
+	keyID      string
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewManager(cfg Config) (*Manager, error) {
+	key, err := loadPrivateKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return &Manager{
+		privateKey: key,
+		keyID:      base64.RawURLEncoding.EncodeToString(sum[:8]),
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		accessTTL:  cfg.AccessTTL,
+		refreshTTL: cfg.RefreshTTL,
+	}, nil
+}
+
+func loadPrivateKey(cfg Config) (*rsa.PrivateKey, error) {
+	data := []byte(cfg.PrivateKeyPEM)
+
+	if len(data) == 0 && cfg.PrivateKeyFile != "" {
+		contents, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		data = contents
+	}
+
+	if len(data) == 0 {
+		log.Println("No JWT signing key configured, generating an ephemeral key; issued tokens will not survive a restart")
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return key, nil
+}
+
+func (m *Manager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+func (m *Manager) IssueAccessToken(userID int, email string, roles []string) (string, error) {
+	now := time.Now()
+
+	claims := Claims{
+		Email: email,
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   strconv.Itoa(userID),
+			Audience:  jwt.ClaimStrings{m.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTTL)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.keyID
+
+	return token.SignedString(m.privateKey)
+}
+
+func (m *Manager) ParseAccessToken(tokenString string) (*Claims, error) {
+	var claims Claims
+
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (any, error) {
+		return &m.privateKey.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+		jwt.WithAudience(m.audience),
+	)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
+
+// NewRefreshToken returns a random refresh token, the hash under which it
+// should be stored and its expiry. Only the hash ever reaches the database.
+func (m *Manager) NewRefreshToken() (string, string, time.Time, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	plain := base64.RawURLEncoding.EncodeToString(buf)
+
+	return plain, HashRefreshToken(plain), time.Now().Add(m.refreshTTL), nil
+}
+
+func HashRefreshToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS exposes the public half of the signing key so other services can
+// verify access tokens without sharing a secret.
+func (m *Manager) JWKS() JWKSet {
+	pub := m.privateKey.PublicKey
+
+	return JWKSet{
+		Keys: []JWK{{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: m.keyID,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	}
+}
@@ -0,0 +1,186 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testKeyPEM(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return key, string(pem.EncodeToMemory(block))
+}
+
+func testManager(t *testing.T, keyPEM string) *Manager {
+	t.Helper()
+
+	m, err := NewManager(Config{
+		PrivateKeyPEM: keyPEM,
+		Issuer:        "authentication-service",
+		Audience:      "go-microservices",
+		AccessTTL:     15 * time.Minute,
+		RefreshTTL:    24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAccessTokenRoundTrip(t *testing.T) {
+	key, keyPEM := testKeyPEM(t)
+	m := testManager(t, keyPEM)
+
+	signed, err := m.IssueAccessToken(42, "ada@example.com", []string{"admin", "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := m.ParseAccessToken(signed)
+	if err != nil {
+		t.Fatalf("ParseAccessToken: %v", err)
+	}
+	if claims.Subject != "42" || claims.Email != "ada@example.com" || strings.Join(claims.Roles, ",") != "admin,user" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if claims.Issuer != "authentication-service" || len(claims.Audience) != 1 || claims.Audience[0] != "go-microservices" {
+		t.Errorf("issuer %q, audience %v", claims.Issuer, claims.Audience)
+	}
+	if got := claims.ExpiresAt.Sub(claims.IssuedAt.Time); got != 15*time.Minute {
+		t.Errorf("token lives %s, want the access TTL", got)
+	}
+
+	// The token is verifiable with nothing but the public key.
+	parsed, err := jwt.Parse(signed, func(tok *jwt.Token) (any, error) {
+		if tok.Header["kid"] != m.keyID {
+			t.Errorf("kid header = %v, want %s", tok.Header["kid"], m.keyID)
+		}
+		return &key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil || !parsed.Valid {
+		t.Errorf("public key rejected the token: %v", err)
+	}
+}
+
+func TestParseAccessTokenRejects(t *testing.T) {
+	_, keyPEM := testKeyPEM(t)
+	m := testManager(t, keyPEM)
+
+	_, otherPEM := testKeyPEM(t)
+	other := testManager(t, otherPEM)
+
+	sign := func(mutate func(*Claims), method jwt.SigningMethod, key any) string {
+		now := time.Now()
+		claims := Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    m.issuer,
+				Subject:   "1",
+				Audience:  jwt.ClaimStrings{m.audience},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+		}
+		mutate(&claims)
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	keep := func(*Claims) {}
+
+	foreign, err := other.IssueAccessToken(1, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		token string
+	}{
+		{"garbage", "not.a.token"},
+		{"other key", foreign},
+		{"expired", sign(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }, jwt.SigningMethodRS256, m.privateKey)},
+		{"wrong issuer", sign(func(c *Claims) { c.Issuer = "someone-else" }, jwt.SigningMethodRS256, m.privateKey)},
+		{"wrong audience", sign(func(c *Claims) { c.Audience = jwt.ClaimStrings{"other"} }, jwt.SigningMethodRS256, m.privateKey)},
+		{"hmac", sign(keep, jwt.SigningMethodHS256, []byte("secret"))},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := m.ParseAccessToken(c.token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("ParseAccessToken = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	key, keyPEM := testKeyPEM(t)
+	m := testManager(t, keyPEM)
+
+	set := m.JWKS()
+	if len(set.Keys) != 1 {
+		t.Fatalf("JWKS has %d keys, want 1", len(set.Keys))
+	}
+	jwk := set.Keys[0]
+	if jwk.Kty != "RSA" || jwk.Use != "sig" || jwk.Alg != "RS256" || jwk.Kid != m.keyID {
+		t.Errorf("jwk = %+v", jwk)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		t.Fatalf("n: %v", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		t.Fatalf("e: %v", err)
+	}
+	if new(big.Int).SetBytes(n).Cmp(key.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != key.E {
+		t.Error("JWKS does not publish the signing key")
+	}
+
+	// The key id is derived from the key, so it is stable across restarts.
+	if again := testManager(t, keyPEM); again.keyID != m.keyID {
+		t.Errorf("key id changed from %s to %s for the same key", m.keyID, again.keyID)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	_, keyPEM := testKeyPEM(t)
+	m := testManager(t, keyPEM)
+
+	plain, hash, expiresAt, err := m.NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != HashRefreshToken(plain) || hash == plain {
+		t.Error("refresh token hash does not match its plain text")
+	}
+	if until := time.Until(expiresAt); until < 23*time.Hour || until > 24*time.Hour {
+		t.Errorf("refresh token expires in %s, want the refresh TTL", until)
+	}
+
+	again, _, _, err := m.NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == plain {
+		t.Error("two refresh tokens were identical")
+	}
+}
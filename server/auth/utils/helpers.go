@@ -50,6 +50,10 @@ func ErrorJSON(w http.ResponseWriter, err error, status ...int) error {
 
 	statuscode := http.StatusBadRequest
 
+	if len(status) > 0 {
+		statuscode = status[0]
+	}
+
 	payload := types.JsonResponse{
 		Error:   true,
 		Message: err.Error(),
@@ -5,7 +5,13 @@ go 1.24.0
 require (
 	github.com/go-chi/chi/v5 v5.2.1
 	github.com/go-chi/cors v1.2.1
+	github.com/golang-jwt/jwt/v5 v5.2.2
 	github.com/joho/godotenv v1.5.1
 )
 
-require github.com/rabbitmq/amqp091-go v1.10.0
+require (
+	github.com/kjsingh03/go-microservices v0.0.0-00010101000000-000000000000
+	github.com/rabbitmq/amqp091-go v1.10.0
+)
+
+replace github.com/kjsingh03/go-microservices => ../
@@ -3,16 +3,22 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"service-broker/internal/auth"
 	"service-broker/internal/config"
+	"service-broker/internal/downstream"
+	"service-broker/internal/event"
 	"service-broker/internal/handler"
 	"service-broker/internal/router"
 	"service-broker/internal/service"
 	"syscall"
 	"time"
+
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 )
 
 type App struct {
@@ -35,7 +41,10 @@ func New() (*App, error) {
 
 	handlers := handler.New(services)
 
-	r := router.New(handlers)
+	keys := auth.NewKeySet(cfg.Auth.JWKSURL, cfg.Auth.JWKSCacheTTL, cfg.Services.Timeout)
+	verifier := auth.NewVerifier(keys, cfg.Auth.Issuer, cfg.Auth.Audience)
+
+	r := router.New(handlers, verifier)
 
 	server := &http.Server{
 		Addr:    ":"+cfg.Server.Port,
@@ -50,21 +59,42 @@ func New() (*App, error) {
 }
 
 func initServices(cfg *config.Config) (*service.Services, error) {
-	rabbitService, err := service.NewRabbitService(cfg.Rabbit)
-
-	if err != nil {
-		return nil, err
+	supervisor := rabbitmq.NewSupervisor(cfg.RabbitMQ.URL)
+	rabbitService := service.NewRabbitService(supervisor, event.EmitterOptions{
+		Channels:       cfg.RabbitMQ.PublishChannels,
+		Buffer:         cfg.RabbitMQ.PublishBuffer,
+		BatchSize:      cfg.RabbitMQ.PublishBatchSize,
+		EnqueueTimeout: cfg.RabbitMQ.PublishEnqueueTimeout,
+	})
+
+	if err := supervisor.Start(cfg.RabbitMQ.ConnectionRetry); err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ after %d attempts: %w", cfg.RabbitMQ.ConnectionRetry, err)
+	}
+	log.Printf("Successfully connected to RabbitMQ at %s:%s", cfg.RabbitMQ.Host, cfg.RabbitMQ.Port)
+
+	opts := downstream.Options{
+		Timeout:          cfg.Services.Timeout,
+		MaxRetries:       cfg.Services.RetryCount,
+		BaseDelay:        cfg.Services.RetryBaseDelay,
+		MaxDelay:         cfg.Services.RetryMaxDelay,
+		FailureThreshold: cfg.Services.BreakerThreshold,
+		OpenTimeout:      cfg.Services.BreakerOpenTimeout,
 	}
 
-	authService := service.NewAuthService(cfg.Services.AuthURL, cfg.Services.Timeout, cfg.Services.RetryCount, "")
-	logService := service.NewLogService(cfg.Services.LogURL, cfg.Services.Timeout, cfg.Services.RetryCount)
-	mailService := service.NewMailService(cfg.Services.MailURL, cfg.Services.Timeout, cfg.Services.RetryCount, "")
+	authClient := downstream.New("auth", cfg.Services.AuthURL, opts)
+	logClient := downstream.New("logger", cfg.Services.LogURL, opts)
+	mailClient := downstream.New("mailer", cfg.Services.MailURL, opts)
+
+	authService := service.NewAuthService(authClient, cfg.Services.AuthAPIKey)
+	logService := service.NewLogService(logClient)
+	mailService := service.NewMailService(mailClient, "")
 
 	return &service.Services{
 		AuthService:   authService,
 		LogService:    logService,
 		MailService:   mailService,
 		RabbitService: rabbitService,
+		Downstreams:   []*downstream.Client{authClient, logClient, mailClient},
 	}, nil
 }
 
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval stops a flood of tokens with unknown key IDs from
+// turning into a flood of requests against the auth service.
+const minRefreshInterval = 10 * time.Second
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// KeySet is a cached view of the auth service's JWKS endpoint.
+type KeySet struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+
+	refreshMu sync.Mutex
+}
+
+func NewKeySet(url string, ttl time.Duration, timeout time.Duration) *KeySet {
+	return &KeySet{
+		url:  url,
+		ttl:  ttl,
+		keys: make(map[string]*rsa.PublicKey),
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Key returns the public key for kid, refreshing the cache when it is stale
+// or does not know the key yet. Stale keys keep being served if the auth
+// service cannot be reached.
+func (k *KeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	key, fresh := k.lookup(kid)
+	if key != nil && fresh {
+		return key, nil
+	}
+
+	if err := k.refresh(ctx); err != nil {
+		if key != nil {
+			return key, nil
+		}
+		return nil, err
+	}
+
+	key, _ = k.lookup(kid)
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+func (k *KeySet) lookup(kid string) (*rsa.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.keys[kid], time.Since(k.fetchedAt) < k.ttl
+}
+
+func (k *KeySet) refresh(ctx context.Context) error {
+	k.refreshMu.Lock()
+	defer k.refreshMu.Unlock()
+
+	k.mu.RLock()
+	recent := time.Since(k.attemptedAt) < minRefreshInterval
+	k.mu.RUnlock()
+	if recent {
+		return nil
+	}
+
+	k.mu.Lock()
+	k.attemptedAt = time.Now()
+	k.mu.Unlock()
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+
+	return nil
+}
+
+func (k *KeySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+
+		pub, err := key.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in JWKS: %w", key.Kid, err)
+		}
+		keys[key.Kid] = pub
+	}
+
+	return keys, nil
+}
+
+func (j jwk) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
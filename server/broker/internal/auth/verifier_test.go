@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "authentication-service"
+	testAudience = "go-microservices"
+)
+
+type testKey struct {
+	kid string
+	key *rsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, key: key}
+}
+
+// sign issues a token like the auth service does, after mutate has had a
+// chance to break it.
+func (k testKey) sign(t *testing.T, mutate func(*jwt.RegisteredClaims)) string {
+	t.Helper()
+
+	now := time.Now()
+	registered := jwt.RegisteredClaims{
+		Issuer:    testIssuer,
+		Subject:   "42",
+		Audience:  jwt.ClaimStrings{testAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}
+	if mutate != nil {
+		mutate(&registered)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims{Email: "ada@example.com", RegisteredClaims: registered})
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// jwksServer serves the public halves of whichever keys it currently holds
+// and counts the fetches.
+type jwksServer struct {
+	*httptest.Server
+	fetches atomic.Int32
+
+	mu     sync.Mutex
+	keys   []testKey
+	status int
+}
+
+func newJWKSServer(t *testing.T, keys ...testKey) *jwksServer {
+	s := &jwksServer{keys: keys, status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.fetches.Add(1)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			return
+		}
+
+		var set jwkSet
+		for _, k := range s.keys {
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA",
+				Kid: k.kid,
+				N:   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) serve(status int, keys ...testKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.keys = keys
+}
+
+func TestVerify(t *testing.T) {
+	current := newTestKey(t, "current")
+	other := newTestKey(t, "other")
+	server := newJWKSServer(t, current)
+	verifier := NewVerifier(NewKeySet(server.URL, time.Hour, time.Second), testIssuer, testAudience)
+
+	// A token signed by "other" but claiming the current key id.
+	forged := testKey{kid: current.kid, key: other.key}
+
+	cases := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid", current.sign(t, nil), true},
+		{"bad issuer", current.sign(t, func(c *jwt.RegisteredClaims) { c.Issuer = "someone-else" }), false},
+		{"bad audience", current.sign(t, func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"other-app"} }), false},
+		{"expired", current.sign(t, func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }), false},
+		{"no expiry", current.sign(t, func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil }), false},
+		{"no subject", current.sign(t, func(c *jwt.RegisteredClaims) { c.Subject = "" }), false},
+		{"wrong signature", forged.sign(t, nil), false},
+		{"unknown kid", other.sign(t, nil), false},
+		{"garbage", "not.a.token", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			identity, err := verifier.Verify(context.Background(), c.token)
+			if !c.valid {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("Verify = %v, %v; want ErrInvalidToken", identity, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if identity.UserID != "42" || identity.Email != "ada@example.com" {
+				t.Errorf("identity = %+v", identity)
+			}
+		})
+	}
+}
+
+func TestKeySetRefetchesUnknownKid(t *testing.T) {
+	old := newTestKey(t, "old")
+	rotated := newTestKey(t, "rotated")
+	server := newJWKSServer(t, old)
+	keys := NewKeySet(server.URL, time.Hour, time.Second)
+	verifier := NewVerifier(keys, testIssuer, testAudience)
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, old.sign(t, nil)); err != nil {
+		t.Fatalf("Verify with the published key: %v", err)
+	}
+	if _, err := verifier.Verify(ctx, old.sign(t, nil)); err != nil {
+		t.Fatalf("second Verify: %v", err)
+	}
+	if n := server.fetches.Load(); n != 1 {
+		t.Fatalf("fetched JWKS %d times, want the cached set reused", n)
+	}
+
+	// The auth service rotates its key. A token with the new kid triggers a
+	// refetch even though the cache is fresh.
+	server.serve(http.StatusOK, old, rotated)
+	keys.attemptedAt = time.Time{}
+	if _, err := verifier.Verify(ctx, rotated.sign(t, nil)); err != nil {
+		t.Fatalf("Verify with the rotated key: %v", err)
+	}
+	if n := server.fetches.Load(); n != 2 {
+		t.Errorf("fetched JWKS %d times, want one refetch for the new kid", n)
+	}
+
+	// Unknown kids arriving in a burst refetch at most once per interval.
+	for range 5 {
+		if _, err := verifier.Verify(ctx, newTestKey(t, "bogus").sign(t, nil)); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("Verify with an unknown kid = %v, want ErrInvalidToken", err)
+		}
+	}
+	if n := server.fetches.Load(); n != 2 {
+		t.Errorf("fetched JWKS %d times, want unknown kids rate limited", n)
+	}
+}
+
+func TestKeySetServesStaleKeysWhenAuthIsDown(t *testing.T) {
+	key := newTestKey(t, "current")
+	server := newJWKSServer(t, key)
+	keys := NewKeySet(server.URL, time.Millisecond, time.Second)
+	verifier := NewVerifier(keys, testIssuer, testAudience)
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, key.sign(t, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	server.serve(http.StatusInternalServerError)
+	time.Sleep(5 * time.Millisecond)
+	keys.attemptedAt = time.Time{}
+
+	if _, err := verifier.Verify(ctx, key.sign(t, nil)); err != nil {
+		t.Errorf("Verify with a stale key while JWKS is down: %v", err)
+	}
+	if n := server.fetches.Load(); n != 2 {
+		t.Errorf("fetched JWKS %d times, want a refresh attempt for the stale set", n)
+	}
+}
@@ -3,13 +3,11 @@ package config
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
-	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type Config struct {
@@ -17,7 +15,7 @@ type Config struct {
 	Server      ServerConfig
 	RabbitMQ    RabbitMQConfig
 	Services    ServicesConfig
-	Rabbit      *amqp.Connection
+	Auth        AuthConfig
 }
 
 type ServerConfig struct {
@@ -34,14 +32,32 @@ type RabbitMQConfig struct {
 	VHost           string
 	ConnectionName  string
 	ConnectionRetry int
+
+	PublishChannels       int
+	PublishBuffer         int
+	PublishBatchSize      int
+	PublishEnqueueTimeout time.Duration
 }
 
 type ServicesConfig struct {
 	AuthURL    string
+	AuthAPIKey string
 	LogURL     string
 	MailURL    string
 	Timeout    time.Duration
 	RetryCount int
+
+	RetryBaseDelay     time.Duration
+	RetryMaxDelay      time.Duration
+	BreakerThreshold   int
+	BreakerOpenTimeout time.Duration
+}
+
+type AuthConfig struct {
+	JWKSURL      string
+	JWKSCacheTTL time.Duration
+	Issuer       string
+	Audience     string
 }
 
 // Load loads the configuration from environment variables
@@ -63,23 +79,38 @@ func Load() (*Config, error) {
 			VHost:           GetEnvVar("RABBITMQ_VHOST", "/"),
 			ConnectionName:  GetEnvVar("RABBITMQ_CONNECTION_NAME", "broker-service"),
 			ConnectionRetry: GetEnvInt("RABBITMQ_CONNECTION_RETRY", 5),
+
+			PublishChannels:       GetEnvInt("RABBITMQ_PUBLISH_CHANNELS", 4),
+			PublishBuffer:         GetEnvInt("RABBITMQ_PUBLISH_BUFFER", 1024),
+			PublishBatchSize:      GetEnvInt("RABBITMQ_PUBLISH_BATCH_SIZE", 64),
+			PublishEnqueueTimeout: GetEnvDuration("RABBITMQ_PUBLISH_ENQUEUE_TIMEOUT", 250*time.Millisecond),
 		},
 		Services: ServicesConfig{
 			AuthURL:    GetEnvVar("AUTH_SERVICE_URL", "http://authentication-service"),
+			AuthAPIKey: GetEnvVar("AUTH_SERVICE_API_KEY", ""),
 			LogURL:     GetEnvVar("LOG_SERVICE_URL", "http://logger-service/api/v1"),
 			MailURL:    GetEnvVar("MAIL_SERVICE_URL", "http://mailer-service/api/v1"),
 			Timeout:    GetEnvDuration("SERVICE_TIMEOUT", 30*time.Second),
 			RetryCount: GetEnvInt("SERVICE_RETRYCOUNT", 5),
+
+			RetryBaseDelay:     GetEnvDuration("SERVICE_RETRY_BASE_DELAY", 200*time.Millisecond),
+			RetryMaxDelay:      GetEnvDuration("SERVICE_RETRY_MAX_DELAY", 5*time.Second),
+			BreakerThreshold:   GetEnvInt("SERVICE_BREAKER_THRESHOLD", 5),
+			BreakerOpenTimeout: GetEnvDuration("SERVICE_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Auth: AuthConfig{
+			JWKSURL:      GetEnvVar("AUTH_JWKS_URL", ""),
+			JWKSCacheTTL: GetEnvDuration("AUTH_JWKS_CACHE_TTL", 10*time.Minute),
+			Issuer:       GetEnvVar("JWT_ISSUER", "authentication-service"),
+			Audience:     GetEnvVar("JWT_AUDIENCE", "go-microservices"),
 		},
 	}
 
-	cfg.RabbitMQ.URL = fmt.Sprintf("amqp://%s:%s@%s:%s%s", cfg.RabbitMQ.Username, cfg.RabbitMQ.Password, cfg.RabbitMQ.Host, cfg.RabbitMQ.Port, cfg.RabbitMQ.VHost)
-
-	conn, err := ConnectRabbitMQ(cfg.RabbitMQ)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	if cfg.Auth.JWKSURL == "" {
+		cfg.Auth.JWKSURL = cfg.Services.AuthURL + "/.well-known/jwks.json"
 	}
-	cfg.Rabbit = conn
+
+	cfg.RabbitMQ.URL = fmt.Sprintf("amqp://%s:%s@%s:%s%s", cfg.RabbitMQ.Username, cfg.RabbitMQ.Password, cfg.RabbitMQ.Host, cfg.RabbitMQ.Port, cfg.RabbitMQ.VHost)
 
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -88,24 +119,6 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
-func ConnectRabbitMQ(cfg RabbitMQConfig) (*amqp.Connection, error) {
-	var conn *amqp.Connection
-	var err error
-
-	for i := 0; i < cfg.ConnectionRetry; i++ {
-		conn, err = amqp.Dial(cfg.URL)
-		if err == nil {
-			log.Printf("Successfully connected to RabbitMQ at %s:%s", cfg.Host, cfg.Port)
-			return conn, nil
-		}
-
-		if i < cfg.ConnectionRetry-1 {
-			time.Sleep(2 * time.Second)
-		}
-	}
-
-	return nil, fmt.Errorf("failed to connect to RabbitMQ after %d attempts: %w", cfg.ConnectionRetry, err)
-}
 func (c *Config) Validate() error {
 	if c.Server.Port == "" {
 		return fmt.Errorf("server port is required")
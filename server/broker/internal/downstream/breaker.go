@@ -0,0 +1,144 @@
+package downstream
+
+import (
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker is a consecutive-failure circuit breaker. Once open it rejects
+// calls until openTimeout has passed, then lets a single probe through
+// (half-open); the probe's outcome closes or re-opens the circuit.
+type Breaker struct {
+	mu          sync.Mutex
+	state       State
+	failures    int
+	threshold   int
+	openTimeout time.Duration
+	openedAt    time.Time
+	probing     bool
+	lastError   string
+}
+
+func NewBreaker(threshold int, openTimeout time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// Allow reports whether a call may proceed.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+}
+
+func (b *Breaker) Failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if err != nil {
+		b.lastError = err.Error()
+	}
+
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+		b.probing = false
+	}
+}
+
+// Cancel releases a call that ended without saying anything about the
+// service's health, such as one whose caller gave up. A cancelled probe
+// returns the circuit to open without restarting the open timeout, so the
+// next call probes again; otherwise Allow would wait forever for a verdict.
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.probing {
+		b.state = StateOpen
+		b.probing = false
+	}
+}
+
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		State:               b.state.String(),
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.openTimeout)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+
+	return status
+}
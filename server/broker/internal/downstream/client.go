@@ -0,0 +1,202 @@
+package downstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type Options struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// Client is the HTTP client the broker uses for every downstream service.
+// It retries transient failures with exponential backoff and jitter and
+// fails fast through a circuit breaker while the service is unhealthy.
+type Client struct {
+	name    string
+	baseURL string
+	opts    Options
+	http    *http.Client
+	breaker *Breaker
+}
+
+func New(name, baseURL string, opts Options) *Client {
+	return &Client{
+		name:    name,
+		baseURL: baseURL,
+		opts:    opts,
+		http: &http.Client{
+			Timeout: opts.Timeout,
+		},
+		breaker: NewBreaker(opts.FailureThreshold, opts.OpenTimeout),
+	}
+}
+
+type Request struct {
+	Method string
+	Path   string
+	Body   any
+	Header http.Header
+	// Idempotent marks a request as safe to retry even though its method
+	// is not, e.g. a read-only POST.
+	Idempotent bool
+}
+
+func (r Request) idempotent() bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return r.Idempotent
+}
+
+// Do sends req and returns the final response. Responses with a 5xx or 429
+// status are returned to the caller once retries are exhausted, so the
+// caller still decides how to report them.
+func (c *Client) Do(ctx context.Context, req Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := json.Marshal(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s request: %w", c.name, err)
+		}
+		body = data
+	}
+
+	var last error
+	for attempt := 0; ; attempt++ {
+		if !c.breaker.Allow() {
+			if last != nil {
+				// An earlier attempt opened the breaker; its failure is
+				// the one worth reporting.
+				return nil, fmt.Errorf("failed to call %s: %w: %w", c.name, ErrCircuitOpen, last)
+			}
+			return nil, fmt.Errorf("%s: %w", c.name, ErrCircuitOpen)
+		}
+
+		resp, err := c.send(ctx, req, body)
+		if ctx.Err() != nil {
+			// The caller gave up; that says nothing about the service's health.
+			c.breaker.Cancel()
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+
+		failure := err
+		if err == nil && transientStatus(resp.StatusCode) {
+			failure = fmt.Errorf("%s returned status %d", c.name, resp.StatusCode)
+		}
+
+		if failure == nil {
+			c.breaker.Success()
+			return resp, nil
+		}
+
+		c.breaker.Failure(failure)
+		last = failure
+
+		retryable := req.idempotent() || (err != nil && notSent(err))
+		if !retryable || attempt >= c.opts.MaxRetries {
+			if err != nil {
+				return nil, fmt.Errorf("failed to call %s: %w", c.name, err)
+			}
+			return resp, nil
+		}
+
+		delay := c.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, req Request, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, c.baseURL+req.Path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range req.Header {
+		httpReq.Header[key] = values
+	}
+	if body != nil && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.http.Do(httpReq)
+}
+
+// backoff returns a full-jitter exponential delay, honouring Retry-After
+// when the service sends one.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			return min(time.Duration(seconds)*time.Second, c.opts.MaxDelay)
+		}
+	}
+
+	ceiling := c.opts.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > c.opts.MaxDelay {
+		ceiling = c.opts.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
+
+func transientStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+}
+
+// notSent reports whether err happened before the request reached the
+// service, which makes even non-idempotent requests safe to retry.
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+type Status struct {
+	Name    string `json:"name"`
+	BaseURL string `json:"base_url"`
+	BreakerStatus
+}
+
+func (c *Client) Status() Status {
+	return Status{
+		Name:          c.name,
+		BaseURL:       c.baseURL,
+		BreakerStatus: c.breaker.Status(),
+	}
+}
@@ -0,0 +1,136 @@
+package downstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerCancelReleasesProbe(t *testing.T) {
+	b := NewBreaker(1, 10*time.Millisecond)
+	b.Failure(errors.New("boom"))
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatal("Allow after the open timeout = false, want a probe")
+	}
+	if b.Allow() {
+		t.Fatal("Allow during the probe = true, want false")
+	}
+
+	b.Cancel()
+	if got := b.Status().State; got != "open" {
+		t.Fatalf("state after Cancel = %s, want open", got)
+	}
+	if !b.Allow() {
+		t.Fatal("Allow after a cancelled probe = false, want another probe")
+	}
+}
+
+func TestBreakerCancelWhenClosed(t *testing.T) {
+	b := NewBreaker(3, time.Minute)
+	b.Cancel()
+
+	if got := b.Status().State; got != "closed" {
+		t.Fatalf("state = %s, want closed", got)
+	}
+	if !b.Allow() {
+		t.Fatal("Allow = false, want true")
+	}
+}
+
+// A half-open probe whose caller cancels must not leave the breaker
+// waiting for a verdict that never comes.
+func TestClientCancelledProbeDoesNotWedgeBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	release := make(chan struct{})
+	defer close(release)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case healthy.Load():
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/slow":
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := New("test", server.URL, Options{
+		Timeout:          time.Second,
+		FailureThreshold: 1,
+		OpenTimeout:      10 * time.Millisecond,
+	})
+
+	resp, err := client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/fail"})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if got := client.Status().State; got != "open" {
+		t.Fatalf("state after a 500 = %s, want open", got)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	// This call is the probe; its caller gives up while it is in flight.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Do(ctx, Request{Method: http.MethodGet, Path: "/slow"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("cancelled probe returned %v, want context.DeadlineExceeded", err)
+	}
+
+	healthy.Store(true)
+	resp, err = client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/ok"})
+	if err != nil {
+		t.Fatalf("Do after a cancelled probe: %v", err)
+	}
+	resp.Body.Close()
+	if got := client.Status().State; got != "closed" {
+		t.Fatalf("state after a successful probe = %s, want closed", got)
+	}
+}
+
+// A retry refused because the previous attempt opened the breaker reports
+// that attempt's failure, not just the open circuit.
+func TestClientReportsFailureThatOpenedBreaker(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New("test", server.URL, Options{
+		Timeout:          time.Second,
+		MaxRetries:       3,
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+	})
+
+	_, err := client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/"})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do = %v, want ErrCircuitOpen", err)
+	}
+	if !strings.Contains(err.Error(), "returned status 503") {
+		t.Errorf("Do = %v, want the 503 that opened the breaker", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server was called %d times, want 1", n)
+	}
+
+	// A later call never reached the service, so there is nothing else to say.
+	if _, err := client.Do(context.Background(), Request{Method: http.MethodGet, Path: "/"}); err == nil || strings.Contains(err.Error(), "503") {
+		t.Errorf("Do with the breaker already open = %v, want a bare ErrCircuitOpen", err)
+	}
+}
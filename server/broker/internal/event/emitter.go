@@ -1,30 +1,95 @@
 package event
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	// ErrBufferFull is returned when the outbound buffer stays full for the
+	// whole enqueue timeout; callers should shed load rather than queue more.
+	ErrBufferFull    = errors.New("outbound publish buffer is full")
+	ErrEmitterClosed = errors.New("event emitter is closed")
+)
+
+type EmitterOptions struct {
+	// Channels is the number of confirm-mode channels publishing in parallel.
+	Channels int
+	// Buffer is how many messages may wait for a free channel.
+	Buffer int
+	// BatchSize caps how many messages a channel publishes before waiting
+	// for their confirms together.
+	BatchSize int
+	// EnqueueTimeout is how long Push waits for room in a full buffer.
+	EnqueueTimeout time.Duration
+	// ConfirmTimeout bounds the wait for a batch's publisher confirms.
+	ConfirmTimeout time.Duration
+}
+
+type publishRequest struct {
+	ctx        context.Context
+	routingKey string
+	body       []byte
+	result     chan error
+}
+
+// EventEmitter publishes to logs_topic through a fixed pool of long-lived
+// channels. Each channel drains the shared buffer in batches, publishes the
+// whole batch and then waits for its confirms, so a round trip is paid per
+// batch rather than per message.
 type EventEmitter struct {
-	connection *amqp.Connection
+	supervisor *rabbitmq.Supervisor
+	opts       EmitterOptions
+	queue      chan *publishRequest
+	seq        atomic.Uint64
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	// closeMu orders enqueue against Close: senders register under the read
+	// lock while done is open, and Close waits for them before draining.
+	closeMu sync.RWMutex
+	senders sync.WaitGroup
 }
 
-func NewEventEmitter(conn *amqp.Connection) (*EventEmitter, error) {
+// NewEventEmitter registers the exchange setup with the supervisor so the
+// exchange is declared again after every reconnect.
+func NewEventEmitter(supervisor *rabbitmq.Supervisor, opts EmitterOptions) *EventEmitter {
+	opts.Channels = max(opts.Channels, 1)
+	opts.Buffer = max(opts.Buffer, 0)
+	opts.BatchSize = max(opts.BatchSize, 1)
+	if opts.ConfirmTimeout <= 0 {
+		opts.ConfirmTimeout = 10 * time.Second
+	}
+
 	emitter := &EventEmitter{
-		connection: conn,
+		supervisor: supervisor,
+		opts:       opts,
+		queue:      make(chan *publishRequest, opts.Buffer),
+		done:       make(chan struct{}),
 	}
 
-	// Setup exchange and queues
-	if err := emitter.setup(); err != nil {
-		return nil, fmt.Errorf("failed to setup emitter: %w", err)
+	supervisor.OnConnect(emitter.setup)
+
+	for i := 0; i < opts.Channels; i++ {
+		emitter.wg.Add(1)
+		go emitter.worker()
 	}
 
-	return emitter, nil
+	return emitter
 }
 
-func (e *EventEmitter) setup() error {
-	ch, err := e.connection.Channel()
+func (e *EventEmitter) setup(conn *amqp.Connection) error {
+	ch, err := conn.Channel()
 	if err != nil {
 		return fmt.Errorf("failed to open channel: %w", err)
 	}
@@ -33,23 +98,251 @@ func (e *EventEmitter) setup() error {
 	return declareExchange(ch)
 }
 
-func (e *EventEmitter) Push(body, routingKey string) error {
-	ch, err := e.connection.Channel()
+// Push publishes a persistent message and waits for the RabbitMQ publisher
+// confirm, so a nil error means the message reached a durable queue. If ctx
+// ends after the message was handed to a channel it may still be delivered.
+func (e *EventEmitter) Push(ctx context.Context, body, routingKey string) error {
+	req := &publishRequest{
+		ctx:        ctx,
+		routingKey: routingKey,
+		body:       []byte(body),
+		result:     make(chan error, 1),
+	}
+
+	if err := e.enqueue(ctx, req); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *EventEmitter) enqueue(ctx context.Context, req *publishRequest) error {
+	e.closeMu.RLock()
+	select {
+	case <-e.done:
+		e.closeMu.RUnlock()
+		return ErrEmitterClosed
+	default:
+	}
+	e.senders.Add(1)
+	e.closeMu.RUnlock()
+	defer e.senders.Done()
+
+	select {
+	case <-e.done:
+		return ErrEmitterClosed
+	case e.queue <- req:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(e.opts.EnqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-e.done:
+		return ErrEmitterClosed
+	case e.queue <- req:
+		return nil
+	case <-timer.C:
+		return ErrBufferFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the publishing channels after their current batch and fails
+// anything still buffered.
+func (e *EventEmitter) Close() {
+	e.closeOnce.Do(func() {
+		e.closeMu.Lock()
+		close(e.done)
+		e.closeMu.Unlock()
+		e.wg.Wait()
+
+		// A sender's select may still pick the queue over done, so drain
+		// only once every sender has returned.
+		e.senders.Wait()
+
+		for {
+			select {
+			case req := <-e.queue:
+				req.result <- ErrEmitterClosed
+			default:
+				return
+			}
+		}
+	})
+}
+
+func (e *EventEmitter) worker() {
+	defer e.wg.Done()
+
+	var pc *publishChannel
+	defer func() {
+		if pc != nil {
+			pc.ch.Close()
+		}
+	}()
+
+	batch := make([]*publishRequest, 0, e.opts.BatchSize)
+
+	for {
+		batch = batch[:0]
+
+		select {
+		case <-e.done:
+			return
+		case req := <-e.queue:
+			batch = append(batch, req)
+		}
+
+	fill:
+		for len(batch) < e.opts.BatchSize {
+			select {
+			case req := <-e.queue:
+				batch = append(batch, req)
+			default:
+				break fill
+			}
+		}
+
+		if pc == nil || pc.ch.IsClosed() {
+			var err error
+			if pc, err = e.openChannel(); err != nil {
+				fail(batch, err)
+				continue
+			}
+		}
+
+		if err := e.publish(pc, batch); err != nil {
+			// The channel is in an unknown state; start the next batch on
+			// a fresh one.
+			pc.ch.Close()
+			pc = nil
+		}
+	}
+}
+
+type publishChannel struct {
+	ch      *amqp.Channel
+	returns chan amqp.Return
+}
+
+func (e *EventEmitter) openChannel() (*publishChannel, error) {
+	conn, err := e.supervisor.Connection()
 	if err != nil {
-		return fmt.Errorf("failed to open channel: %w", err)
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
-	defer ch.Close()
 
-	msg := amqp.Publishing{
-		ContentType: "text/plain",
-		Body:        []byte(body),
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
 	}
 
-	return ch.Publish(
-		"logs_topic", // exchange
-		routingKey,   // routing key
-		false,        // mandatory
-		false,        // immediate
-		msg,          // message
-	)
-}
\ No newline at end of file
+	// With mandatory set, a message that matches no queue binding is
+	// returned before it is confirmed instead of being silently dropped.
+	// The buffer holds a full batch so returns never block the confirms.
+	return &publishChannel{
+		ch:      ch,
+		returns: ch.NotifyReturn(make(chan amqp.Return, e.opts.BatchSize)),
+	}, nil
+}
+
+// publish sends the batch, waits for every confirm and reports each
+// message's outcome. A non-nil error means the channel should be replaced.
+func (e *EventEmitter) publish(pc *publishChannel, batch []*publishRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.opts.ConfirmTimeout)
+	defer cancel()
+
+	confirms := make([]*amqp.DeferredConfirmation, len(batch))
+	results := make([]error, len(batch))
+	byID := make(map[string]int, len(batch))
+
+	var channelErr error
+
+	for i, req := range batch {
+		if channelErr != nil {
+			results[i] = channelErr
+			continue
+		}
+		if err := req.ctx.Err(); err != nil {
+			results[i] = err
+			continue
+		}
+
+		id := strconv.FormatUint(e.seq.Add(1), 10)
+		byID[id] = i
+
+		confirm, err := pc.ch.PublishWithDeferredConfirmWithContext(
+			ctx,
+			"logs_topic",   // exchange
+			req.routingKey, // routing key
+			true,           // mandatory
+			false,          // immediate
+			amqp.Publishing{
+				MessageId:    id,
+				ContentType:  "application/json",
+				DeliveryMode: amqp.Persistent,
+				Timestamp:    time.Now(),
+				Body:         req.body,
+			},
+		)
+		if err != nil {
+			channelErr = fmt.Errorf("failed to publish message: %w", err)
+			results[i] = channelErr
+			continue
+		}
+		confirms[i] = confirm
+	}
+
+	for i, confirm := range confirms {
+		if confirm == nil {
+			continue
+		}
+
+		acked, err := confirm.WaitContext(ctx)
+		switch {
+		case err != nil:
+			channelErr = fmt.Errorf("failed to confirm message: %w", err)
+			results[i] = channelErr
+		case !acked:
+			results[i] = errors.New("message was rejected by RabbitMQ")
+		}
+	}
+
+	// Returns for a message arrive before its confirm, so every return
+	// belonging to this batch is already buffered.
+	for drained := false; !drained; {
+		select {
+		case ret := <-pc.returns:
+			if i, ok := byID[ret.MessageId]; ok {
+				results[i] = fmt.Errorf("message was unroutable: no queue bound for %q (%s)", ret.RoutingKey, ret.ReplyText)
+			}
+		default:
+			drained = true
+		}
+	}
+
+	for i, req := range batch {
+		req.result <- results[i]
+	}
+
+	return channelErr
+}
+
+func fail(batch []*publishRequest, err error) {
+	for _, req := range batch {
+		req.result <- err
+	}
+}
@@ -3,22 +3,53 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"service-broker/internal/auth"
+	"service-broker/internal/downstream"
+	"service-broker/internal/event"
 	"service-broker/internal/helper"
 	"service-broker/internal/service"
 	"service-broker/types"
+	"strings"
 	"time"
+
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 )
 
+// serviceName is recorded on log entries that do not name their producer.
+const serviceName = "broker-service"
+
 type Handler struct {
 	services *service.Services
+	hostname string
 }
 
 func New(services *service.Services) *Handler {
+	hostname, _ := os.Hostname()
+	
 	return &Handler{
 		services: services,
+		hostname: hostname,
+	}
+}
+
+// stampLog attributes a log entry to the broker and to the request that
+// carried it, using X-Request-ID and the W3C traceparent header.
+func (h *Handler) stampLog(r *http.Request, logPayload *types.LogPayload) {
+	logPayload.Stamp(serviceName, h.hostname, r.Header.Get("X-Request-ID"), traceID(r.Header.Get("traceparent")))
+}
+
+// traceID extracts the trace id from a traceparent header of the form
+// version-traceid-parentid-flags.
+func traceID(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
 	}
+	return parts[1]
 }
 
 func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
@@ -31,6 +62,39 @@ func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// Status reports the circuit breaker state of each downstream service.
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	response := types.JsonResponse{
+		Error:   false,
+		Message: "downstream status",
+		Data:    h.services.Status(),
+	}
+	
+	helper.WriteJSON(w, http.StatusOK, response)
+}
+
+// Health reports 503 while the broker cannot reach RabbitMQ, since the log
+// action cannot be served until the supervisor reconnects.
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	rabbit := h.services.RabbitService.Status()
+	
+	statusCode := http.StatusOK
+	if rabbit.State != rabbitmq.StateConnected {
+		statusCode = http.StatusServiceUnavailable
+	}
+	
+	response := types.JsonResponse{
+		Error:   statusCode != http.StatusOK,
+		Message: "broker health",
+		Data: map[string]any{
+			"rabbitmq":    rabbit,
+			"downstreams": h.services.Status(),
+		},
+	}
+	
+	helper.WriteJSON(w, statusCode, response)
+}
+
 func (h *Handler) HandleSubmission(w http.ResponseWriter, r *http.Request) {
 	var requestPayload types.RequestPayload
 	
@@ -46,9 +110,27 @@ func (h *Handler) HandleSubmission(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 	
+	if requestPayload.RequiresAuth() {
+		identity, ok := auth.IdentityFromContext(r.Context())
+		if !ok {
+			helper.ErrorJSON(w, fmt.Errorf("a valid bearer token is required for action '%s'", requestPayload.Action), http.StatusUnauthorized)
+			return
+		}
+	
+		permission := types.ActionPermissions[requestPayload.Action]
+		if err := h.services.AuthService.ValidatePermissions(ctx, identity.UserID, permission); err != nil {
+			if errors.Is(err, service.ErrPermissionDenied) {
+				helper.ErrorJSON(w, fmt.Errorf("permission '%s' is required for action '%s'", permission, requestPayload.Action), http.StatusForbidden)
+				return
+			}
+			helper.ErrorJSON(w, err, errorStatus(err, http.StatusBadGateway))
+			return
+		}
+	}
+	
 	switch requestPayload.Action {
 	case "auth":
 		if requestPayload.Auth == nil {
@@ -56,17 +138,25 @@ func (h *Handler) HandleSubmission(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		h.authenticate(ctx, w, *requestPayload.Auth)
+	case "register":
+		if requestPayload.Register == nil {
+			helper.ErrorJSON(w, fmt.Errorf("register payload is required"), http.StatusBadRequest)
+			return
+		}
+		h.register(ctx, w, *requestPayload.Register)
 	case "log":
 		if requestPayload.Log == nil {
 			helper.ErrorJSON(w, fmt.Errorf("log payload is required"), http.StatusBadRequest)
 			return
 		}
+		h.stampLog(r, requestPayload.Log)
 		h.logEventViaRabbit(ctx, w, *requestPayload.Log)
 	case "logdirect":
 		if requestPayload.Log == nil {
 			helper.ErrorJSON(w, fmt.Errorf("log payload is required"), http.StatusBadRequest)
 			return
 		}
+		h.stampLog(r, requestPayload.Log)
 		h.logItem(ctx, w, *requestPayload.Log)
 	case "mail":
 		if requestPayload.Mail == nil {
@@ -74,6 +164,8 @@ func (h *Handler) HandleSubmission(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		h.sendMail(ctx, w, *requestPayload.Mail)
+	case "droplogs":
+		h.dropLogs(ctx, w)
 	default:
 		helper.ErrorJSONWithExample(w, fmt.Errorf("unknown action '%s'", requestPayload.Action), helper.GetValidActionExamples(), http.StatusBadRequest)
 	}
@@ -82,7 +174,7 @@ func (h *Handler) HandleSubmission(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) authenticate(ctx context.Context, w http.ResponseWriter, authPayload types.AuthPayload) {
 	authResp, err := h.services.AuthService.Authenticate(ctx, authPayload)
 	if err != nil {
-		helper.ErrorJSON(w, err, http.StatusUnauthorized)
+		helper.ErrorJSON(w, err, errorStatus(err, http.StatusUnauthorized))
 		return
 	}
 	
@@ -95,12 +187,30 @@ func (h *Handler) authenticate(ctx context.Context, w http.ResponseWriter, authP
 	helper.WriteJSON(w, http.StatusAccepted, response)
 }
 
+func (h *Handler) register(ctx context.Context, w http.ResponseWriter, registerPayload types.RegisterPayload) {
+	user, err := h.services.AuthService.Register(ctx, registerPayload)
+	if err != nil {
+		statusCode := errorStatus(err, http.StatusBadGateway)
+		if errors.Is(err, service.ErrUserExists) {
+			statusCode = http.StatusConflict
+		}
+		helper.ErrorJSON(w, err, statusCode)
+		return
+	}
+	
+	response := types.JsonResponse{
+		Error:   false,
+		Message: "Registered!",
+		Data:    user,
+	}
+	
+	helper.WriteJSON(w, http.StatusCreated, response)
+}
+
 func (h *Handler) logItem(ctx context.Context, w http.ResponseWriter, logPayload types.LogPayload) {
-	err := h.services.LogService.Log(ctx, "INFO", logPayload.Name, map[string]interface{}{
-		"data": logPayload.Data,
-	})
+	err := h.services.LogService.Log(ctx, logPayload)
 	if err != nil {
-		helper.ErrorJSON(w, err, http.StatusInternalServerError)
+		helper.ErrorJSON(w, err, errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 	
@@ -115,7 +225,7 @@ func (h *Handler) logItem(ctx context.Context, w http.ResponseWriter, logPayload
 func (h *Handler) logEventViaRabbit(ctx context.Context, w http.ResponseWriter, logPayload types.LogPayload) {
 	err := h.services.RabbitService.PublishLog(ctx, logPayload)
 	if err != nil {
-		helper.ErrorJSON(w, err, http.StatusInternalServerError)
+		helper.ErrorJSON(w, err, errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 	
@@ -130,7 +240,7 @@ func (h *Handler) logEventViaRabbit(ctx context.Context, w http.ResponseWriter,
 func (h *Handler) sendMail(ctx context.Context, w http.ResponseWriter, mailPayload types.MailPayload) {
 	err := h.services.MailService.SendEmail(ctx, mailPayload.To, mailPayload.Subject, mailPayload.Message)
 	if err != nil {
-		helper.ErrorJSON(w, err, http.StatusInternalServerError)
+		helper.ErrorJSON(w, err, errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 	
@@ -140,4 +250,28 @@ func (h *Handler) sendMail(ctx context.Context, w http.ResponseWriter, mailPaylo
 	}
 	
 	helper.WriteJSON(w, http.StatusAccepted, response)
-}
\ No newline at end of file
+}
+
+func (h *Handler) dropLogs(ctx context.Context, w http.ResponseWriter) {
+	err := h.services.LogService.DropAll(ctx)
+	if err != nil {
+		helper.ErrorJSON(w, err, errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+	
+	response := types.JsonResponse{
+		Error:   false,
+		Message: "all logs dropped",
+	}
+	
+	helper.WriteJSON(w, http.StatusAccepted, response)
+}
+
+// errorStatus maps an open circuit, a lost RabbitMQ connection or a full
+// publish buffer to 503 so clients know to back off, falling back to statusCode for any other error.
+func errorStatus(err error, statusCode int) int {
+	if errors.Is(err, downstream.ErrCircuitOpen) || errors.Is(err, rabbitmq.ErrNotConnected) || errors.Is(err, event.ErrBufferFull) {
+		return http.StatusServiceUnavailable
+	}
+	return statusCode
+}
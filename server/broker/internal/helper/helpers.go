@@ -50,6 +50,10 @@ func ErrorJSON(w http.ResponseWriter, err error, status ...int) error {
 
 	statuscode := http.StatusBadRequest
 
+	if len(status) > 0 {
+		statuscode = status[0]
+	}
+
 	payload := types.JsonResponse{
 		Error:   true,
 		Message: err.Error(),
@@ -97,18 +101,34 @@ func GetValidActionExamples() map[string]interface{} {
 					"password": "your-password",
 				},
 			},
+			"register": map[string]interface{}{
+				"action": "register",
+				"register": map[string]string{
+					"email":      "user@example.com",
+					"password":   "at-least-8-chars",
+					"first_name": "Jane",
+					"last_name":  "Doe",
+				},
+			},
 			"log": map[string]interface{}{
 				"action": "log",
-				"log": map[string]string{
-					"name": "user-action",
-					"data": "User logged in successfully",
+				"log": map[string]interface{}{
+					"name":    "user-action",
+					"data":    "User logged in successfully",
+					"level":   "INFO",
+					"service": "auth-service",
+					"tags":    []string{"login"},
+					"attributes": map[string]interface{}{
+						"user_id": "42",
+					},
 				},
 			},
 			"logdirect": map[string]interface{}{
 				"action": "logdirect",
 				"log": map[string]string{
-					"name": "system-event",
-					"data": "Direct log to service",
+					"name":  "system-event",
+					"data":  "Direct log to service",
+					"level": "WARNING",
 				},
 			},
 			"mail": map[string]interface{}{
@@ -120,6 +140,9 @@ func GetValidActionExamples() map[string]interface{} {
 					"message": "Welcome to our service",
 				},
 			},
+			"droplogs": map[string]interface{}{
+				"action": "droplogs",
+			},
 		},
 	}
 }
\ No newline at end of file
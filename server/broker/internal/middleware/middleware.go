@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"service-broker/internal/auth"
+	"strings"
 )
 
 func CorsMiddleware() func(http.Handler) http.Handler {
@@ -9,7 +12,7 @@ func CorsMiddleware() func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, traceparent")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -19,4 +22,29 @@ func CorsMiddleware() func(http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// Authenticate verifies the bearer token, if any, and stores the caller's
+// identity in the request context. Requests without a valid token are passed
+// through anonymously; handlers decide which actions require an identity.
+func Authenticate(verifier *auth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			tokenString, found := strings.CutPrefix(header, "Bearer ")
+			if !found || tokenString == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			identity, err := verifier.Verify(r.Context(), tokenString)
+			if err != nil {
+				log.Printf("Rejected bearer token: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+		})
+	}
+}
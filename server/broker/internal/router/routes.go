@@ -3,18 +3,22 @@ package router
 
 import (
 	"net/http"
+	"service-broker/internal/auth"
 	"service-broker/internal/handler"
 	"service-broker/internal/middleware"
 
 	"github.com/go-chi/chi/v5"
 )
 
-func New(h *handler.Handler) http.Handler {
+func New(h *handler.Handler, verifier *auth.Verifier) http.Handler {
 	mux := chi.NewRouter()
 
 	mux.Use(middleware.CorsMiddleware())
+	mux.Use(middleware.Authenticate(verifier))
 
 	mux.Get("/", h.Home)
+	mux.Get("/status", h.Status)
+	mux.Get("/health", h.Health)
 	mux.Post("/handle", h.HandleSubmission)
 
 	return mux
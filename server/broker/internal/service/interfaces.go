@@ -3,16 +3,20 @@ package service
 import (
 	"context"
 	"service-broker/types"
+
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 )
 
 // Service interfaces
 type AuthService interface {
 	Authenticate(ctx context.Context, authPayload types.AuthPayload) (*types.AuthResponse, error)
+	Register(ctx context.Context, registerPayload types.RegisterPayload) (*types.User, error)
 	ValidatePermissions(ctx context.Context, userID string, resource string) error
 }
 
 type LogService interface {
-	Log(ctx context.Context, level string, message string, data map[string]interface{}) error
+	Log(ctx context.Context, entry types.LogPayload) error
+	DropAll(ctx context.Context) error
 }
 
 type MailService interface {
@@ -22,5 +26,6 @@ type MailService interface {
 
 type RabbitService interface {
 	PublishLog(ctx context.Context, payload types.LogPayload) error
+	Status() rabbitmq.Status
 	Close() error
 }
\ No newline at end of file
@@ -8,27 +8,23 @@ import (
 	"service-broker/types"
 	"sync"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 )
 
 type rabbitService struct {
-	conn     *amqp.Connection
-	emitter  *event.EventEmitter
-	mu       sync.RWMutex
-	closed   bool
+	supervisor *rabbitmq.Supervisor
+	emitter    *event.EventEmitter
+	mu         sync.RWMutex
+	closed     bool
 }
 
-// NewRabbitService creates a new RabbitMQ service
-func NewRabbitService(conn *amqp.Connection) (RabbitService, error) {
-	emitter, err := event.NewEventEmitter(conn)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create event emitter: %w", err)
-	}
-
+// NewRabbitService creates a new RabbitMQ service. It must be created
+// before the supervisor is started so its setup runs on every connection.
+func NewRabbitService(supervisor *rabbitmq.Supervisor, opts event.EmitterOptions) RabbitService {
 	return &rabbitService{
-		conn:    conn,
-		emitter: emitter,
-	}, nil
+		supervisor: supervisor,
+		emitter:    event.NewEventEmitter(supervisor, opts),
+	}
 }
 
 func (s *rabbitService) PublishLog(ctx context.Context, payload types.LogPayload) error {
@@ -44,14 +40,7 @@ func (s *rabbitService) PublishLog(ctx context.Context, payload types.LogPayload
 		return fmt.Errorf("failed to marshal log payload: %w", err)
 	}
 
-	// Check if context is cancelled
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-	}
-
-	if err := s.emitter.Push(string(jsonData), "log.INFO"); err != nil {
+	if err := s.emitter.Push(ctx, string(jsonData), payload.RoutingKey()); err != nil {
 		return fmt.Errorf("failed to publish to queue: %w", err)
 	}
 
@@ -67,8 +56,10 @@ func (s *rabbitService) Close() error {
 	}
 
 	s.closed = true
-	if s.conn != nil {
-		return s.conn.Close()
-	}
-	return nil
+	s.emitter.Close()
+	return s.supervisor.Close()
+}
+
+func (s *rabbitService) Status() rabbitmq.Status {
+	return s.supervisor.Status()
 }
\ No newline at end of file
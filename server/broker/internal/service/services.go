@@ -1,13 +1,20 @@
 package service
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"service-broker/internal/downstream"
 	"service-broker/types"
-	"time"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrUserExists       = errors.New("a user with this email already exists")
 )
 
 // Services holds all service implementations
@@ -16,6 +23,16 @@ type Services struct {
 	LogService    LogService
 	MailService   MailService
 	RabbitService RabbitService
+	Downstreams   []*downstream.Client
+}
+
+// Status reports the circuit breaker state of every downstream service.
+func (s *Services) Status() []downstream.Status {
+	statuses := make([]downstream.Status, 0, len(s.Downstreams))
+	for _, client := range s.Downstreams {
+		statuses = append(statuses, client.Status())
+	}
+	return statuses
 }
 
 // Close closes all services
@@ -28,110 +45,201 @@ func (s *Services) Close() error {
 
 // Auth Service Implementation
 type authService struct {
-	baseURL string
-	timeout time.Duration
-	retries int
-	apiKey  string
-	client  *http.Client
+	client *downstream.Client
+	apiKey string
 }
 
-func NewAuthService(baseURL string, timeout time.Duration, retries int, apiKey string) AuthService {
+func NewAuthService(client *downstream.Client, apiKey string) AuthService {
 	return &authService{
-		baseURL: baseURL,
-		timeout: timeout,
-		retries: retries,
-		apiKey:  apiKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client: client,
+		apiKey: apiKey,
+	}
+}
+
+// authUser mirrors the user model returned by the auth service.
+type authUser struct {
+	ID        int    `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Active    bool   `json:"active"`
+}
+
+func (u *authUser) toUser() *types.User {
+	return &types.User{
+		ID:    strconv.Itoa(u.ID),
+		Email: u.Email,
+		Name:  strings.TrimSpace(u.FirstName + " " + u.LastName),
 	}
 }
 
 func (s *authService) Authenticate(ctx context.Context, authPayload types.AuthPayload) (*types.AuthResponse, error) {
-	jsonData, err := json.Marshal(authPayload)
+	resp, err := s.post(ctx, "/login", authPayload, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal auth payload: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/register", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("invalid credentials")
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth service returned status %d: %s", resp.StatusCode, authErrorMessage(resp))
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	if s.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	var loginResp struct {
+		User         *authUser `json:"user"`
+		AccessToken  string    `json:"access_token"`
+		RefreshToken string    `json:"refresh_token"`
+		ExpiresIn    int64     `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&loginResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	resp, err := s.client.Do(req)
+	if loginResp.User == nil {
+		return nil, fmt.Errorf("auth service response is missing the user")
+	}
+
+	return &types.AuthResponse{
+		Valid:        true,
+		User:         loginResp.User.toUser(),
+		Token:        loginResp.AccessToken,
+		RefreshToken: loginResp.RefreshToken,
+		ExpiresIn:    loginResp.ExpiresIn,
+	}, nil
+}
+
+func (s *authService) Register(ctx context.Context, registerPayload types.RegisterPayload) (*types.User, error) {
+	resp, err := s.post(ctx, "/register", registerPayload, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call auth service: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("invalid credentials")
-	} else if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("auth service returned status %d", resp.StatusCode)
+	if resp.StatusCode == http.StatusConflict {
+		return nil, ErrUserExists
+	} else if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("auth service returned status %d: %s", resp.StatusCode, authErrorMessage(resp))
 	}
 
-	var authResp types.AuthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
+	var registerResp struct {
+		UserID int `json:"user_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&registerResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &authResp, nil
+	user := authUser{
+		ID:        registerResp.UserID,
+		Email:     registerPayload.Email,
+		FirstName: registerPayload.FirstName,
+		LastName:  registerPayload.LastName,
+	}
+
+	return user.toUser(), nil
 }
 
+func (s *authService) post(ctx context.Context, path string, payload any, idempotent bool) (*http.Response, error) {
+	return s.client.Do(ctx, downstream.Request{
+		Method:     http.MethodPost,
+		Path:       path,
+		Body:       payload,
+		Header:     bearer(s.apiKey),
+		Idempotent: idempotent,
+	})
+}
+
+// authErrorMessage extracts the message from an auth service error body.
+func authErrorMessage(resp *http.Response) string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Message == "" {
+		return http.StatusText(resp.StatusCode)
+	}
+	return body.Message
+}
+
+// ValidatePermissions asks the auth service whether userID holds the
+// permission named by resource and returns ErrPermissionDenied if not.
 func (s *authService) ValidatePermissions(ctx context.Context, userID string, resource string) error {
-	// Implement permission validation logic based on your requirements
+	id, err := strconv.Atoi(userID)
+	if err != nil {
+		return fmt.Errorf("%w: invalid user id %q", ErrPermissionDenied, userID)
+	}
+
+	resp, err := s.post(ctx, "/authorize", map[string]any{
+		"user_id":    id,
+		"permission": resource,
+	}, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Allowed bool `json:"allowed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if !result.Allowed {
+		return ErrPermissionDenied
+	}
+
 	return nil
 }
 
 // Log Service Implementation
 type logService struct {
-	baseURL string
-	timeout time.Duration
-	retries int
-	client  *http.Client
+	client *downstream.Client
 }
 
-func NewLogService(baseURL string, timeout time.Duration, retries int) LogService {
+func NewLogService(client *downstream.Client) LogService {
 	return &logService{
-		baseURL: baseURL,
-		timeout: timeout,
-		retries: retries,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client: client,
 	}
 }
 
-func (s *logService) Log(ctx context.Context, level string, message string, data map[string]interface{}) error {
-	logEntry := types.LogPayload{
-		Name: message,
-		Data: fmt.Sprintf("%v", data),
-	}
-
-	jsonData, err := json.Marshal(logEntry)
+func (s *logService) Log(ctx context.Context, entry types.LogPayload) error {
+	resp, err := s.client.Do(ctx, downstream.Request{
+		Method: http.MethodPost,
+		Path:   "/logs",
+		Body:   entry,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal log entry: %w", err)
+		return err
 	}
+	defer resp.Body.Close()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/logs", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+	// The logger answers 201 Created; any 2xx means the entry was taken.
+	if !successStatus(resp.StatusCode) {
+		return fmt.Errorf("log service returned status %d", resp.StatusCode)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	return nil
+}
+
+func successStatus(code int) bool {
+	return code >= 200 && code < 300
+}
 
-	resp, err := s.client.Do(req)
+func (s *logService) DropAll(ctx context.Context) error {
+	resp, err := s.client.Do(ctx, downstream.Request{
+		Method: http.MethodDelete,
+		Path:   "/logs/drop?confirm=true",
+	})
 	if err != nil {
-		return fmt.Errorf("failed to call log service: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("log service returned status %d", resp.StatusCode)
 	}
 
@@ -140,22 +248,14 @@ func (s *logService) Log(ctx context.Context, level string, message string, data
 
 // Mail Service Implementation
 type mailService struct {
-	baseURL string
-	timeout time.Duration
-	retries int
-	apiKey  string
-	client  *http.Client
+	client *downstream.Client
+	apiKey string
 }
 
-func NewMailService(baseURL string, timeout time.Duration, retries int, apiKey string) MailService {
+func NewMailService(client *downstream.Client, apiKey string) MailService {
 	return &mailService{
-		baseURL: baseURL,
-		timeout: timeout,
-		retries: retries,
-		apiKey:  apiKey,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+		client: client,
+		apiKey: apiKey,
 	}
 }
 
@@ -166,24 +266,14 @@ func (s *mailService) SendEmail(ctx context.Context, to, subject, body string) e
 		Message: body,
 	}
 
-	jsonData, err := json.Marshal(mailPayload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal mail payload: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/send", bytes.NewBuffer(jsonData))
+	resp, err := s.client.Do(ctx, downstream.Request{
+		Method: http.MethodPost,
+		Path:   "/send",
+		Body:   mailPayload,
+		Header: bearer(s.apiKey),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if s.apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+s.apiKey)
-	}
-
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to call mail service: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
@@ -198,3 +288,10 @@ func (s *mailService) SendTemplateEmail(ctx context.Context, to, templateID, sub
 	// Implement template email sending logic based on your mail service
 	return s.SendEmail(ctx, to, subject, fmt.Sprintf("Template: %s, Data: %v", templateID, data))
 }
+
+func bearer(apiKey string) http.Header {
+	if apiKey == "" {
+		return nil
+	}
+	return http.Header{"Authorization": []string{"Bearer " + apiKey}}
+}
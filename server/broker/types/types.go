@@ -8,10 +8,11 @@ type JsonResponse struct {
 }
 
 type RequestPayload struct {
-	Action string      `json:"action"`
-	Auth   *AuthPayload `json:"auth,omitempty"`
-	Log    *LogPayload  `json:"log,omitempty"`
-	Mail   *MailPayload `json:"mail,omitempty"`
+	Action   string           `json:"action"`
+	Auth     *AuthPayload     `json:"auth,omitempty"`
+	Register *RegisterPayload `json:"register,omitempty"`
+	Log      *LogPayload      `json:"log,omitempty"`
+	Mail     *MailPayload     `json:"mail,omitempty"`
 }
 
 type MailPayload struct {
@@ -26,9 +27,25 @@ type AuthPayload struct {
 	Password string `json:"password"`
 }
 
+type RegisterPayload struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+}
+
+// LogPayload is a log entry. Name and data are all older clients send; the
+// other fields add structure the logger stores and filters on.
 type LogPayload struct {
-	Name string `json:"name"`
-	Data string `json:"data"`
+	Name       string         `json:"name"`
+	Data       string         `json:"data,omitempty"`
+	Level      string         `json:"level,omitempty"`
+	Service    string         `json:"service,omitempty"`
+	Host       string         `json:"host,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 type User struct {
@@ -38,7 +55,9 @@ type User struct {
 }
 
 type AuthResponse struct {
-	Valid bool   `json:"valid"`
-	User  *User  `json:"user,omitempty"`
-	Token string `json:"token,omitempty"`
+	Valid        bool   `json:"valid"`
+	User         *User  `json:"user,omitempty"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
 }
\ No newline at end of file
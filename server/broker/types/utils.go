@@ -5,7 +5,35 @@ import (
 	"strings"
 )
 
-var ValidActions = []string{"auth", "log", "logdirect", "mail"}
+var ValidActions = []string{"auth", "register", "log", "logdirect", "mail", "droplogs"}
+
+const minPasswordLength = 8
+
+// LogLevels are the severities the listener binds as log.<LEVEL>.
+var LogLevels = []string{"DEBUG", "INFO", "WARNING", "ERROR"}
+
+const DefaultLogLevel = "INFO"
+
+// ProtectedActions can only be invoked with a verified bearer token.
+var ProtectedActions = []string{"log", "logdirect", "mail", "droplogs"}
+
+// ActionPermissions maps protected actions to the auth service permission
+// the caller must hold.
+var ActionPermissions = map[string]string{
+	"log":       "log:write",
+	"logdirect": "log:write",
+	"mail":      "mail:send",
+	"droplogs":  "logs:drop",
+}
+
+func (r *RequestPayload) RequiresAuth() bool {
+	for _, action := range ProtectedActions {
+		if r.Action == action {
+			return true
+		}
+	}
+	return false
+}
 
 func (r *RequestPayload) Validate() error {
 	// Check if action is provided
@@ -33,6 +61,11 @@ func (r *RequestPayload) Validate() error {
 			return fmt.Errorf("auth payload is required for action 'auth'")
 		}
 		return r.Auth.Validate()
+	case "register":
+		if r.Register == nil {
+			return fmt.Errorf("register payload is required for action 'register'")
+		}
+		return r.Register.Validate()
 	case "log", "logdirect":
 		if r.Log == nil {
 			return fmt.Errorf("log payload is required for action '%s'", r.Action)
@@ -61,14 +94,69 @@ func (a *AuthPayload) Validate() error {
 	return nil
 }
 
+func (p *RegisterPayload) Validate() error {
+	if p.Email == "" {
+		return fmt.Errorf("email is required for registration")
+	}
+	if !strings.Contains(p.Email, "@") {
+		return fmt.Errorf("invalid email format")
+	}
+	if len(p.Password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
 func (l *LogPayload) Validate() error {
 	if l.Name == "" {
 		return fmt.Errorf("log name is required")
 	}
-	if l.Data == "" {
-		return fmt.Errorf("log data is required")
+	if l.Data == "" && len(l.Attributes) == 0 {
+		return fmt.Errorf("log data or attributes are required")
 	}
-	return nil
+	for key, value := range l.Attributes {
+		switch value.(type) {
+		case string, bool, float64:
+		default:
+			return fmt.Errorf("log attribute '%s' must be a string, number or bool", key)
+		}
+	}
+
+	l.Level = strings.ToUpper(strings.TrimSpace(l.Level))
+	if l.Level == "" {
+		l.Level = DefaultLogLevel
+	}
+	for _, level := range LogLevels {
+		if l.Level == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid log level '%s'. Valid levels: %s", l.Level, strings.Join(LogLevels, ", "))
+}
+
+// Stamp fills in the producer fields the client left empty.
+func (l *LogPayload) Stamp(service, host, requestID, traceID string) {
+	if l.Service == "" {
+		l.Service = service
+	}
+	if l.Host == "" {
+		l.Host = host
+	}
+	if l.RequestID == "" {
+		l.RequestID = requestID
+	}
+	if l.TraceID == "" {
+		l.TraceID = traceID
+	}
+}
+
+// RoutingKey is the topic the payload is published under.
+func (l *LogPayload) RoutingKey() string {
+	level := l.Level
+	if level == "" {
+		level = DefaultLogLevel
+	}
+	return "log." + level
 }
 
 func (m *MailPayload) Validate() error {
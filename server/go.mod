@@ -1,3 +1,5 @@
 module github.com/kjsingh03/go-microservices
 
 go 1.24.0
+
+require github.com/rabbitmq/amqp091-go v1.10.0
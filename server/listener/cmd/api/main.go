@@ -1,30 +1,77 @@
 package main
 
 import (
+	"listener/internal/admin"
 	"listener/internal/config"
 	"listener/internal/event"
-	"listener/internal/rabbitmq"
 	"log"
+	"net/http"
+
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 )
 
 func main() {
 	cfg := config.Load()
 
-	rabbitConn, err := rabbitmq.Connect(cfg)
+	supervisor := rabbitmq.NewSupervisor(cfg.RabbitMQURL())
+
+	registry, err := newRegistry(cfg)
 	if err != nil {
+		log.Panic("Handler configuration error:", err)
+	}
+
+	consumer := event.NewConsumer(supervisor, registry, event.Options{
+		QueueName:   cfg.QueueName,
+		Prefetch:    cfg.Prefetch,
+		Workers:     cfg.Workers,
+		MaxAttempts: cfg.MaxAttempts,
+		RetryDelays: cfg.RetryDelays,
+	})
+
+	if err := supervisor.Start(cfg.ConnectAttempts); err != nil {
 		log.Panic("RabbitMQ connection error:", err)
 	}
-	defer rabbitConn.Close()
+	defer supervisor.Close()
+
+	go func() {
+		deadLetters := event.NewDeadLetters(supervisor, cfg.QueueName)
+		log.Printf("Admin API listening on :%s", cfg.AdminPort)
+		if err := http.ListenAndServe(":"+cfg.AdminPort, admin.Routes(supervisor, deadLetters, cfg.AdminAPIKey)); err != nil {
+			log.Println("Admin API stopped:", err)
+		}
+	}()
 
 	log.Println("Listening for and consuming RabbitMQ messages...")
 
-	consumer, err := event.NewConsumer(rabbitConn)
+	err = consumer.Listen(cfg.Topics)
 	if err != nil {
-		panic(err)
+		log.Println(err)
+	}
+}
+
+func newRegistry(cfg *config.Config) (*event.Registry, error) {
+	registry := event.NewRegistry(cfg.DefaultHandler)
+
+	client := event.NewHTTPClient(cfg.HTTPTimeout, cfg.Workers)
+
+	logs := event.NewLogForwarder(cfg.LoggerURL, client)
+	registry.Register("log", logs, "log", "event")
+	registry.Register("mail", event.NewMailSender(cfg.MailURL, client), "mail")
+	registry.Register("audit", event.NewAuthAudit(event.NewLogForwarder(cfg.AuditURL, client)), "auth")
+
+	if cfg.WebhookURL != "" {
+		registry.Register("webhook", event.NewWebhook(cfg.WebhookURL, cfg.WebhookSecret, client), "webhook")
 	}
 
-	err = consumer.Listen([]string{"log.INFO", "log.WARNING", "log.ERROR"})
-	if err != nil {
-		log.Println(err)
+	for _, route := range cfg.Routes {
+		if route.Handler == "webhook" && cfg.WebhookURL == "" {
+			log.Printf("WEBHOOK_URL is not set, skipping route %s", route.Pattern)
+			continue
+		}
+		if err := registry.Route(route.Pattern, route.Handler); err != nil {
+			return nil, err
+		}
 	}
+
+	return registry, nil
 }
@@ -4,5 +4,8 @@ go 1.24.0
 
 require (
 	github.com/joho/godotenv v1.5.1
+	github.com/kjsingh03/go-microservices v0.0.0-00010101000000-000000000000
 	github.com/rabbitmq/amqp091-go v1.10.0
 )
+
+replace github.com/kjsingh03/go-microservices => ../
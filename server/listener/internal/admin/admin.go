@@ -0,0 +1,162 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"listener/internal/event"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kjsingh03/go-microservices/rabbitmq"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 1000
+)
+
+type handler struct {
+	supervisor  *rabbitmq.Supervisor
+	deadLetters *event.DeadLetters
+}
+
+// Routes returns the listener's admin API. When apiKey is set every request
+// except the health check must carry it as a bearer token.
+func Routes(supervisor *rabbitmq.Supervisor, deadLetters *event.DeadLetters, apiKey string) http.Handler {
+	h := &handler{
+		supervisor:  supervisor,
+		deadLetters: deadLetters,
+	}
+
+	admin := http.NewServeMux()
+	admin.HandleFunc("GET /dead-letters", h.list)
+	admin.HandleFunc("POST /dead-letters/replay", h.replay)
+	admin.HandleFunc("DELETE /dead-letters", h.purge)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", h.health)
+	mux.Handle("/", requireKey(apiKey, admin))
+
+	return mux
+}
+
+func requireKey(apiKey string, next http.Handler) http.Handler {
+	if apiKey == "" {
+		log.Println("LISTENER_ADMIN_API_KEY is not set, admin endpoints are unauthenticated")
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+			errorJSON(w, errors.New("invalid admin key"), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// health reports 503 while RabbitMQ is unreachable so orchestrators can
+// tell a listener that is reconnecting from one that is consuming.
+func (h *handler) health(w http.ResponseWriter, r *http.Request) {
+	status := h.supervisor.Status()
+
+	code := http.StatusOK
+	if status.State != rabbitmq.StateConnected {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, map[string]any{
+		"healthy":  code == http.StatusOK,
+		"rabbitmq": status,
+	})
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r)
+	if err != nil {
+		errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	letters, err := h.deadLetters.List(limit)
+	if err != nil {
+		errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"count":        len(letters),
+		"dead_letters": letters,
+	})
+}
+
+func (h *handler) replay(w http.ResponseWriter, r *http.Request) {
+	limit, err := limitParam(r)
+	if err != nil {
+		errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	replayed, err := h.deadLetters.Replay(r.Context(), limit)
+	if err != nil {
+		log.Printf("Replayed %d dead letters before failing: %v", replayed, err)
+		errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Replayed %d dead letters", replayed)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"replayed": replayed,
+	})
+}
+
+func (h *handler) purge(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("confirm") != "true" {
+		errorJSON(w, errors.New("purging dead letters requires confirm=true"), http.StatusBadRequest)
+		return
+	}
+
+	purged, err := h.deadLetters.Purge()
+	if err != nil {
+		errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Purged %d dead letters", purged)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"purged": purged,
+	})
+}
+
+func limitParam(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+
+	return min(limit, maxLimit), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func errorJSON(w http.ResponseWriter, err error, status int) {
+	writeJSON(w, status, map[string]any{
+		"error":   true,
+		"message": err.Error(),
+	})
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,24 +16,160 @@ type Config struct {
 	RabbitMQPass string
 	RabbitMQHost string
 	RabbitMQPort string
+	// ConnectAttempts bounds the initial connection only; once connected
+	// the listener reconnects indefinitely.
+	ConnectAttempts int
+
+	QueueName string
+	Prefetch  int
+	Workers   int
+
+	MaxAttempts int
+	RetryDelays []time.Duration
+
+	AdminPort   string
+	AdminAPIKey string
+
+	// Topics are the logs_topic routing keys the queue is bound to. They
+	// default to the route patterns so every route receives messages.
+	Topics []string
+	// Routes map routing key patterns to handler names, tried in order.
+	Routes         []Route
+	DefaultHandler string
+
+	LoggerURL     string
+	AuditURL      string
+	MailURL       string
+	WebhookURL    string
+	WebhookSecret string
+
+	// HTTPTimeout bounds each call a handler makes to another service.
+	HTTPTimeout time.Duration
+}
+
+type Route struct {
+	Pattern string
+	Handler string
 }
 
 func Load() (*Config) {
 	_ = godotenv.Load("../.env")
 
+	routes := getEnvRoutes("LISTENER_ROUTES", "log.*=log,mail.#=mail,auth.#=audit,webhook.#=webhook")
+
 	cfg := &Config{
 		RabbitMQUser: getEnvVar("RABBITMQ_USER", "guest"),
 		RabbitMQPass: getEnvVar("RABBITMQ_PASS", "guest"),
 		RabbitMQHost: getEnvVar("RABBITMQ_HOST", "rabbitmq"),
 		RabbitMQPort: getEnvVar("RABBITMQ_PORT", "5672"),
+
+		ConnectAttempts: getEnvInt("RABBITMQ_CONNECTION_RETRY", 6),
+
+		QueueName: getEnvVar("LISTENER_QUEUE", "listener.logs"),
+		Prefetch:  getEnvInt("LISTENER_PREFETCH", 20),
+		Workers:   getEnvInt("LISTENER_WORKERS", 10),
+
+		MaxAttempts: getEnvInt("LISTENER_MAX_ATTEMPTS", 5),
+		RetryDelays: getEnvDurations("LISTENER_RETRY_DELAYS", []time.Duration{5 * time.Second, 30 * time.Second, 5 * time.Minute}),
+
+		AdminPort:   getEnvVar("LISTENER_ADMIN_PORT", "80"),
+		AdminAPIKey: getEnvVar("LISTENER_ADMIN_API_KEY", ""),
+
+		Topics:         getEnvList("LISTENER_TOPICS", routePatterns(routes)),
+		Routes:         routes,
+		DefaultHandler: getEnvVar("LISTENER_DEFAULT_HANDLER", "log"),
+
+		LoggerURL:     getEnvVar("LOGGER_SERVICE_URL", "http://logger-service/api/v1/logs"),
+		AuditURL:      getEnvVar("AUDIT_SERVICE_URL", "http://logger-service/api/v1/audit"),
+		MailURL:       getEnvVar("MAIL_SERVICE_URL", "http://mailer-service/api/v1"),
+		WebhookURL:    getEnvVar("WEBHOOK_URL", ""),
+		WebhookSecret: getEnvVar("WEBHOOK_SECRET", ""),
+
+		HTTPTimeout: getEnvDuration("LISTENER_HTTP_TIMEOUT", 10*time.Second),
 	}
 
 	return cfg
 }
 
+// RabbitMQURL is the AMQP address of the configured broker.
+func (cfg *Config) RabbitMQURL() string {
+	return fmt.Sprintf("amqp://%s:%s@%s:%s/", cfg.RabbitMQUser, cfg.RabbitMQPass, cfg.RabbitMQHost, cfg.RabbitMQPort)
+}
+
 func getEnvVar(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if val, err := strconv.Atoi(os.Getenv(key)); err == nil && val > 0 {
+		return val
+	}
+	return fallback
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if val, err := time.ParseDuration(os.Getenv(key)); err == nil && val > 0 {
+		return val
+	}
+	return fallback
+}
+
+// getEnvDurations parses a comma separated list such as "5s,30s,5m".
+func getEnvDurations(key string, fallback []time.Duration) []time.Duration {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(val, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			log.Printf("invalid %s entry %q, using defaults", key, part)
+			return fallback
+		}
+		durations = append(durations, d)
+	}
+
+	return durations
+}
+
+func getEnvList(key string, fallback []string) []string {
+	var values []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// getEnvRoutes parses "pattern=handler" pairs such as "log.*=log,mail.#=mail".
+func getEnvRoutes(key, fallback string) []Route {
+	var routes []Route
+	for _, pair := range getEnvList(key, strings.Split(fallback, ",")) {
+		pattern, handler, ok := strings.Cut(pair, "=")
+		if !ok || pattern == "" || handler == "" {
+			log.Printf("invalid %s entry %q, expected pattern=handler", key, pair)
+			continue
+		}
+		routes = append(routes, Route{Pattern: strings.TrimSpace(pattern), Handler: strings.TrimSpace(handler)})
+	}
+
+	return routes
+}
+
+func routePatterns(routes []Route) []string {
+	patterns := make([]string, len(routes))
+	for i, route := range routes {
+		patterns[i] = route.Pattern
+	}
+	return patterns
+}
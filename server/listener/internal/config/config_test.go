@@ -0,0 +1,56 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadServiceURLs(t *testing.T) {
+	// Empty counts as unset, so the defaults apply.
+	t.Setenv("LOGGER_SERVICE_URL", "")
+	t.Setenv("AUDIT_SERVICE_URL", "")
+
+	cfg := Load()
+	if cfg.LoggerURL != "http://logger-service/api/v1/logs" {
+		t.Errorf("default LoggerURL = %q", cfg.LoggerURL)
+	}
+	if cfg.AuditURL != "http://logger-service/api/v1/audit" {
+		t.Errorf("default AuditURL = %q", cfg.AuditURL)
+	}
+
+	t.Setenv("LOGGER_SERVICE_URL", "http://logs.internal:9000/api/v1/logs")
+	t.Setenv("AUDIT_SERVICE_URL", "http://logs.internal:9000/api/v1/audit")
+
+	cfg = Load()
+	if cfg.LoggerURL != "http://logs.internal:9000/api/v1/logs" {
+		t.Errorf("LoggerURL = %q, want the LOGGER_SERVICE_URL value", cfg.LoggerURL)
+	}
+	if cfg.AuditURL != "http://logs.internal:9000/api/v1/audit" {
+		t.Errorf("AuditURL = %q, want the AUDIT_SERVICE_URL value", cfg.AuditURL)
+	}
+}
+
+func TestLoadTopicsCoverRoutes(t *testing.T) {
+	t.Setenv("LISTENER_TOPICS", "")
+	t.Setenv("LISTENER_ROUTES", "")
+
+	cfg := Load()
+	want := []string{"log.*", "mail.#", "auth.#", "webhook.#"}
+	if !slices.Equal(cfg.Topics, want) {
+		t.Errorf("default Topics = %v, want %v", cfg.Topics, want)
+	}
+
+	t.Setenv("LISTENER_ROUTES", "orders.#=log")
+
+	cfg = Load()
+	if !slices.Equal(cfg.Topics, []string{"orders.#"}) {
+		t.Errorf("Topics = %v, want the LISTENER_ROUTES patterns", cfg.Topics)
+	}
+
+	t.Setenv("LISTENER_TOPICS", "log.ERROR")
+
+	cfg = Load()
+	if !slices.Equal(cfg.Topics, []string{"log.ERROR"}) {
+		t.Errorf("Topics = %v, want the LISTENER_TOPICS value", cfg.Topics)
+	}
+}
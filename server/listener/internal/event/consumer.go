@@ -1,61 +1,149 @@
 package event
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/kjsingh03/go-microservices/rabbitmq"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// errUnprocessable marks a message that will never succeed, however often
+// it is redelivered, so it is dead-lettered without being retried.
+var errUnprocessable = errors.New("unprocessable message")
+
+type Options struct {
+	QueueName string
+	Prefetch  int
+	Workers   int
+	// MaxAttempts is how many times a message is tried before it is
+	// dead-lettered. RetryDelays are the delay tiers between attempts; the
+	// last tier is reused once they run out.
+	MaxAttempts int
+	RetryDelays []time.Duration
+}
+
+// handlerTimeout bounds the time a handler may spend on one delivery.
+const handlerTimeout = 30 * time.Second
+
 type Consumer struct {
-	conn *amqp.Connection
-	queueName string
+	supervisor  *rabbitmq.Supervisor
+	registry    *Registry
+	opts        Options
+	retryQueues []string
 }
 
-func NewConsumer(conn *amqp.Connection) (Consumer, error) {
-	consumer := Consumer{
-		conn: conn,
+// NewConsumer registers the consumer's topology with the supervisor so it
+// is declared again on every reconnect. Call it before the supervisor starts.
+func NewConsumer(supervisor *rabbitmq.Supervisor, registry *Registry, opts Options) *Consumer {
+	consumer := &Consumer{
+		supervisor: supervisor,
+		registry:   registry,
+		opts:       opts,
 	}
 
-	err := consumer.setup()
-	if err != nil {
-		return Consumer{}, err
+	for _, delay := range opts.RetryDelays {
+		consumer.retryQueues = append(consumer.retryQueues, retryQueueName(opts.QueueName, delay))
 	}
 
-	return consumer, nil
+	supervisor.OnConnect(consumer.setup)
+
+	return consumer
 }
 
-func (consumer *Consumer) setup() error {
-	channel, err := consumer.conn.Channel()
+func (consumer *Consumer) setup(conn *amqp.Connection) error {
+	channel, err := conn.Channel()
 	if err != nil {
 		return err
 	}
+	defer channel.Close()
 
-	return declareExchange(channel)
+	if err := declareExchange(channel); err != nil {
+		return err
+	}
+
+	if err := declareDeadLetter(channel); err != nil {
+		return err
+	}
+
+	return declareRetryQueues(channel, consumer.opts.QueueName, consumer.opts.RetryDelays)
 }
 
+// Payload is the event body. The structured fields are optional and are
+// passed through to the logger as they were published.
 type Payload struct {
-	Name string `json:"name"`
-	Data string `json:"data"`
+	Name       string         `json:"name"`
+	Data       string         `json:"data"`
+	Level      string         `json:"level,omitempty"`
+	Service    string         `json:"service,omitempty"`
+	Host       string         `json:"host,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// RoutingKey is the key the event was published under.
+	RoutingKey string `json:"-"`
 }
 
+// Listen consumes until the supervisor is closed, resuming on each new
+// connection after RabbitMQ goes away.
 func (consumer *Consumer) Listen(topics []string) error {
-	ch, err := consumer.conn.Channel()
+	for {
+		conn, err := consumer.supervisor.Wait(context.Background())
+		if err != nil {
+			return err
+		}
+
+		err = consumer.consume(conn, topics)
+		log.Println("consumer stopped:", err)
+
+		if !conn.IsClosed() {
+			// The channel failed on a healthy connection; pause so a
+			// persistent error does not spin.
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// consume reads from the durable queue with manual acknowledgements. At
+// most prefetch messages are in flight and they are handled by a fixed pool
+// of workers. It returns when the delivery channel is closed.
+func (consumer *Consumer) consume(conn *amqp.Connection, topics []string) error {
+	ch, err := conn.Channel()
 	if err != nil {
 		return err
 	}
 	defer ch.Close()
 
-	q, err := declareRandomQueue(ch)
+	if err := ch.Qos(consumer.opts.Prefetch, 0, false); err != nil {
+		return err
+	}
+
+	// Retries and dead letters are published on their own confirmed
+	// channel so a delivery is only acked once its copy is safely queued.
+	pub, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer pub.Close()
+
+	if err := pub.Confirm(false); err != nil {
+		return err
+	}
+
+	q, err := declareQueue(ch, consumer.opts.QueueName)
 	if err != nil {
 		return err
 	}
 
 	for _, s := range topics {
-		ch.QueueBind(
+		err := ch.QueueBind(
 			q.Name,
 			s,
 			"logs_topic",
@@ -68,72 +156,129 @@ func (consumer *Consumer) Listen(topics []string) error {
 		}
 	}
 
-	messages, err := ch.Consume(q.Name, "", true, false, false, false, nil)
+	messages, err := ch.Consume(q.Name, "", false, false, false, false, nil)
 	if err != nil {
 		return err
 	}
 
-	forever := make(chan bool)
-	go func() {
-		for d := range messages {
-			var payload Payload
-			_ = json.Unmarshal(d.Body, &payload)
-
-			go handlePayload(payload)
-		}
-	}()
+	var wg sync.WaitGroup
+	for i := 0; i < consumer.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range messages {
+				consumer.handleDelivery(pub, d)
+			}
+		}()
+	}
 
 	fmt.Printf("Waiting for message [Exchange, Queue] [logs_topic, %s]\n", q.Name)
-	<-forever
+	wg.Wait()
 
-	return nil
+	return errors.New("delivery channel closed")
 }
 
-func handlePayload(payload Payload) {
-	switch payload.Name {
-	case "log", "event":
-		// log whatever we get
-		err := logEvent(payload)
-		if err != nil {
-			log.Println(err)
+func (consumer *Consumer) handleDelivery(pub *amqp.Channel, d amqp.Delivery) {
+	var payload Payload
+	err := json.Unmarshal(d.Body, &payload)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", errUnprocessable, err)
+	} else {
+		payload.RoutingKey = originalRoutingKey(d)
+		if payload.Level == "" {
+			payload.Level = levelFromRoutingKey(payload.RoutingKey)
 		}
 
-	case "auth":
-		// authenticate
-
-	// you can have as many cases as you want, as long as you write the logic
+		ctx, cancel := context.WithTimeout(context.Background(), handlerTimeout)
+		err = consumer.registry.Dispatch(ctx, payload)
+		cancel()
+	}
 
-	default:
-		err := logEvent(payload)
-		if err != nil {
-			log.Println(err)
+	if err == nil {
+		if err := d.Ack(false); err != nil {
+			log.Println("failed to ack message:", err)
 		}
+		return
 	}
-}
 
-func logEvent(entry Payload) error {
-	jsonData, _ := json.MarshalIndent(entry, "", "\t")
+	attempt := attempts(d.Headers) + 1
 
-	logServiceURL := "http://logger/log"
+	exchange, routingKey := DeadLetterExchange, ""
+	if !errors.Is(err, errUnprocessable) && attempt < consumer.opts.MaxAttempts && len(consumer.retryQueues) > 0 {
+		tier := min(attempt, len(consumer.retryQueues)) - 1
+		exchange, routingKey = "", consumer.retryQueues[tier]
+		log.Printf("attempt %d failed, retrying via %s: %v", attempt, routingKey, err)
+	} else {
+		log.Printf("attempt %d failed, dead-lettering message: %v", attempt, err)
+	}
 
-	request, err := http.NewRequest("POST", logServiceURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return err
+	if pubErr := republish(pub, exchange, routingKey, d, attempt, err); pubErr != nil {
+		// Nothing holds a copy yet, so hand the original back to RabbitMQ.
+		log.Println("failed to republish message, requeueing:", pubErr)
+		if err := d.Nack(false, true); err != nil {
+			log.Println("failed to nack message:", err)
+		}
+		return
 	}
 
-	request.Header.Set("Content-Type", "application/json")
+	if err := d.Ack(false); err != nil {
+		log.Println("failed to ack message:", err)
+	}
+}
+
+// republish copies d to the given exchange with its attempt count and last
+// error recorded in the headers, and waits for the broker to confirm it.
+func republish(pub *amqp.Channel, exchange, routingKey string, d amqp.Delivery, attempt int, cause error) error {
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[attemptsHeader] = int32(attempt)
+	headers[lastErrorHeader] = cause.Error()
+	if _, ok := headers[routingKeyHeader]; !ok {
+		headers[routingKeyHeader] = d.RoutingKey
+	}
 
-	client := &http.Client{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	response, err := client.Do(request)
+	confirm, err := pub.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    d.Timestamp,
+		Body:         d.Body,
+	})
 	if err != nil {
 		return err
 	}
-	defer response.Body.Close()
 
-	if response.StatusCode != http.StatusAccepted {
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
 		return err
 	}
-	
+	if !acked {
+		return errors.New("publish was not acknowledged")
+	}
+
 	return nil
-}
\ No newline at end of file
+}
+
+// originalRoutingKey is the key the message was first published with,
+// which retries and replays carry in a header.
+func originalRoutingKey(d amqp.Delivery) string {
+	if original, ok := d.Headers[routingKeyHeader].(string); ok {
+		return original
+	}
+	return d.RoutingKey
+}
+
+// levelFromRoutingKey recovers the severity of publishers that only encode
+// it in the log.<LEVEL> routing key.
+func levelFromRoutingKey(key string) string {
+	level, found := strings.CutPrefix(key, "log.")
+	if !found {
+		return ""
+	}
+	return level
+}
@@ -0,0 +1,160 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kjsingh03/go-microservices/rabbitmq"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetter is a message sitting in the dead-letter queue.
+type DeadLetter struct {
+	RoutingKey string          `json:"routing_key"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error,omitempty"`
+	Timestamp  time.Time       `json:"timestamp,omitempty"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// DeadLetters lets operators inspect and recover dead-lettered messages.
+type DeadLetters struct {
+	supervisor *rabbitmq.Supervisor
+	queueName  string
+}
+
+func NewDeadLetters(supervisor *rabbitmq.Supervisor, queueName string) *DeadLetters {
+	return &DeadLetters{
+		supervisor: supervisor,
+		queueName:  queueName,
+	}
+}
+
+func (dl *DeadLetters) channel() (*amqp.Channel, error) {
+	conn, err := dl.supervisor.Connection()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Channel()
+}
+
+// List returns up to limit dead letters without removing them. Messages are
+// fetched unacknowledged and go back to the queue when the channel closes.
+func (dl *DeadLetters) List(limit int) ([]DeadLetter, error) {
+	ch, err := dl.channel()
+	if err != nil {
+		return nil, err
+	}
+	defer ch.Close()
+
+	letters := []DeadLetter{}
+
+	for len(letters) < limit {
+		d, ok, err := ch.Get(DeadLetterQueue, false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		letters = append(letters, toDeadLetter(d))
+	}
+
+	return letters, nil
+}
+
+// Replay moves up to limit dead letters back onto the work queue with a
+// fresh attempt count and reports how many were moved.
+func (dl *DeadLetters) Replay(ctx context.Context, limit int) (int, error) {
+	ch, err := dl.channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	if err := ch.Confirm(false); err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+
+	for replayed < limit {
+		d, ok, err := ch.Get(DeadLetterQueue, false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		headers := amqp.Table{}
+		for k, v := range d.Headers {
+			headers[k] = v
+		}
+		delete(headers, attemptsHeader)
+		delete(headers, lastErrorHeader)
+		delete(headers, "x-death")
+
+		confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", dl.queueName, false, false, amqp.Publishing{
+			Headers:      headers,
+			ContentType:  d.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    d.Timestamp,
+			Body:         d.Body,
+		})
+		if err != nil {
+			return replayed, fmt.Errorf("failed to replay message: %w", err)
+		}
+
+		acked, err := confirm.WaitContext(ctx)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to replay message: %w", err)
+		}
+		if !acked {
+			return replayed, errors.New("failed to replay message: publish was not acknowledged")
+		}
+
+		if err := d.Ack(false); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// Purge drops every dead letter and reports how many were removed.
+func (dl *DeadLetters) Purge() (int, error) {
+	ch, err := dl.channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	return ch.QueuePurge(DeadLetterQueue, false)
+}
+
+func toDeadLetter(d amqp.Delivery) DeadLetter {
+	letter := DeadLetter{
+		RoutingKey: d.RoutingKey,
+		Attempts:   attempts(d.Headers),
+		Timestamp:  d.Timestamp,
+		Body:       d.Body,
+	}
+
+	if key, ok := d.Headers[routingKeyHeader].(string); ok {
+		letter.RoutingKey = key
+	}
+	if lastErr, ok := d.Headers[lastErrorHeader].(string); ok {
+		letter.LastError = lastErr
+	}
+	if !json.Valid(d.Body) {
+		quoted, _ := json.Marshal(string(d.Body))
+		letter.Body = quoted
+	}
+
+	return letter
+}
@@ -1,9 +1,25 @@
 package event
 
 import (
+	"fmt"
+	"time"
+
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	// DeadLetterExchange receives messages that exhausted their retries or
+	// can never be processed; DeadLetterQueue keeps them for operators.
+	DeadLetterExchange = "logs_dlx"
+	DeadLetterQueue    = "logs.dead"
+
+	attemptsHeader  = "x-attempts"
+	lastErrorHeader = "x-last-error"
+	// routingKeyHeader keeps the original routing key, which is lost once a
+	// message has been moved through the retry or dead-letter queues.
+	routingKeyHeader = "x-original-routing-key"
+)
+
 func declareExchange(ch *amqp.Channel) error {
 	return ch.ExchangeDeclare(
 		"logs_topic", // name
@@ -16,13 +32,77 @@ func declareExchange(ch *amqp.Channel) error {
 	)
 }
 
-func declareRandomQueue(ch *amqp.Channel) (amqp.Queue, error) {
+// declareQueue declares a named, durable queue so messages published while
+// the listener is down are kept until it comes back. Rejected messages are
+// dead-lettered rather than dropped.
+func declareQueue(ch *amqp.Channel, name string) (amqp.Queue, error) {
 	return ch.QueueDeclare(
-		"",        // name?
-		false,     // durable?
+		name,      // name?
+		true,      // durable?
 		false,     // delete when unused?
-		true,      // exclusive?
+		false,     // exclusive?
 		false,     // no-wait?
-		nil,       // arguments?
+		amqp.Table{
+			"x-dead-letter-exchange": DeadLetterExchange,
+		}, // arguments?
 	)
-}
\ No newline at end of file
+}
+
+func declareDeadLetter(ch *amqp.Channel) error {
+	err := ch.ExchangeDeclare(
+		DeadLetterExchange, // name
+		"fanout",           // type
+		true,               // durable?
+		false,              // auto-deleted?
+		false,              // internal?
+		false,              // no-wait?
+		nil,                // arguments?
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.QueueDeclare(DeadLetterQueue, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(DeadLetterQueue, "", DeadLetterExchange, false, nil)
+}
+
+// declareRetryQueues declares one delay queue per tier. Messages published
+// to a tier sit there for its TTL and are then dead-lettered through the
+// default exchange straight back onto the work queue.
+func declareRetryQueues(ch *amqp.Channel, queueName string, delays []time.Duration) error {
+	for _, delay := range delays {
+		name := retryQueueName(queueName, delay)
+
+		_, err := ch.QueueDeclare(name, true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func retryQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", queueName, delay)
+}
+
+// attempts returns how many times a message has already failed.
+func attempts(headers amqp.Table) int {
+	switch n := headers[attemptsHeader].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,193 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogForwarder stores events in the logger service.
+type LogForwarder struct {
+	url    string
+	client *http.Client
+}
+
+func NewLogForwarder(loggerURL string, client *http.Client) *LogForwarder {
+	return &LogForwarder{
+		url:    loggerURL,
+		client: client,
+	}
+}
+
+func (f *LogForwarder) Handle(ctx context.Context, payload Payload) error {
+	return postJSON(ctx, f.client, f.url, payload, nil)
+}
+
+// MailSender sends events whose data is a mail message through the mailer
+// service.
+type MailSender struct {
+	url    string
+	client *http.Client
+}
+
+func NewMailSender(mailURL string, client *http.Client) *MailSender {
+	return &MailSender{
+		url:    strings.TrimRight(mailURL, "/") + "/send",
+		client: client,
+	}
+}
+
+type mailMessage struct {
+	From    string `json:"from,omitempty"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+func (m *MailSender) Handle(ctx context.Context, payload Payload) error {
+	var msg mailMessage
+	if err := json.Unmarshal([]byte(payload.Data), &msg); err != nil {
+		return fmt.Errorf("%w: mail data is not a message: %v", errUnprocessable, err)
+	}
+
+	if msg.To == "" || msg.Subject == "" || msg.Message == "" {
+		return fmt.Errorf("%w: mail requires to, subject and message", errUnprocessable)
+	}
+
+	return postJSON(ctx, m.client, m.url, msg, nil)
+}
+
+// AuthAudit records authentication events in the logger's tamper-evident
+// audit trail, which refuses updates and deletes.
+type AuthAudit struct {
+	logs *LogForwarder
+}
+
+func NewAuthAudit(logs *LogForwarder) *AuthAudit {
+	return &AuthAudit{logs: logs}
+}
+
+func (a *AuthAudit) Handle(ctx context.Context, payload Payload) error {
+	event := payload.RoutingKey
+	if event == "" {
+		event = payload.Name
+	}
+
+	entry := payload
+	entry.Name = "audit:" + event
+	if entry.Level == "" {
+		entry.Level = "INFO"
+	}
+	if !slices.Contains(entry.Tags, "audit") {
+		entry.Tags = append(slices.Clone(entry.Tags), "audit")
+	}
+
+	return a.logs.Handle(ctx, entry)
+}
+
+// Webhook posts events to an external URL. When a secret is set the body
+// is signed with HMAC-SHA256 in the X-Signature-256 header.
+type Webhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhook(url, secret string, client *http.Client) *Webhook {
+	return &Webhook{
+		url:    url,
+		secret: secret,
+		client: client,
+	}
+}
+
+func (wh *Webhook) Handle(ctx context.Context, payload Payload) error {
+	body := map[string]any{
+		"event":       payload.Name,
+		"routing_key": payload.RoutingKey,
+		"level":       payload.Level,
+		"data":        payload.Data,
+		"service":     payload.Service,
+		"trace_id":    payload.TraceID,
+		"request_id":  payload.RequestID,
+		"tags":        payload.Tags,
+		"attributes":  payload.Attributes,
+		"sent_at":     time.Now().UTC(),
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnprocessable, err)
+	}
+
+	header := http.Header{}
+	header.Set("X-Event-Key", payload.RoutingKey)
+
+	if wh.secret != "" {
+		mac := hmac.New(sha256.New, []byte(wh.secret))
+		mac.Write(data)
+		header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return postJSON(ctx, wh.client, wh.url, json.RawMessage(data), header)
+}
+
+// NewHTTPClient returns the client shared by every handler. Keeping one
+// transport lets connections to the same service be reused across workers.
+func NewHTTPClient(timeout time.Duration, maxConnsPerHost int) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxConnsPerHost * 4
+	transport.MaxIdleConnsPerHost = maxConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.ResponseHeaderTimeout = timeout
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// postJSON posts body and classifies the response: 2xx succeeds, 5xx, 429
+// and transport errors are retried, and any other status is unprocessable
+// since redelivering the same request will not change the answer.
+func postJSON(ctx context.Context, client *http.Client, url string, body any, header http.Header) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnprocessable, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return nil
+	case response.StatusCode >= http.StatusInternalServerError, response.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%s returned status %d", url, response.StatusCode)
+	default:
+		return fmt.Errorf("%w: %s returned status %d", errUnprocessable, url, response.StatusCode)
+	}
+}
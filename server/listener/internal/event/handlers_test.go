@@ -0,0 +1,139 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// loggerStandIn answers every request with status and records what it
+// received.
+type loggerStandIn struct {
+	*httptest.Server
+	status   int
+	method   string
+	path     string
+	header   http.Header
+	received Payload
+}
+
+func newLoggerStandIn(t *testing.T, status int) *loggerStandIn {
+	t.Helper()
+
+	s := &loggerStandIn{status: status}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.method = r.Method
+		s.path = r.URL.Path
+		s.header = r.Header.Clone()
+		if err := json.NewDecoder(r.Body).Decode(&s.received); err != nil {
+			t.Errorf("decoding the forwarded body: %v", err)
+		}
+		w.WriteHeader(s.status)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestLogForwarderResponseClasses(t *testing.T) {
+	tests := []struct {
+		status        int
+		wantErr       bool
+		unprocessable bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusCreated, false, false},
+		{http.StatusAccepted, false, false},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusBadGateway, true, false},
+		{http.StatusServiceUnavailable, true, false},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusNotFound, true, true},
+		{http.StatusConflict, true, true},
+		{http.StatusUnprocessableEntity, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			logger := newLoggerStandIn(t, tt.status)
+			forwarder := NewLogForwarder(logger.URL+"/api/v1/logs", NewHTTPClient(time.Second, 1))
+
+			err := forwarder.Handle(context.Background(), Payload{Name: "event", Data: "data"})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Handle returned %v, want error %v", err, tt.wantErr)
+			}
+			// Errors that are not unprocessable are retried by the consumer;
+			// unprocessable ones are dead-lettered at once.
+			if got := errors.Is(err, errUnprocessable); got != tt.unprocessable {
+				t.Fatalf("errors.Is(%v, errUnprocessable) = %v, want %v", err, got, tt.unprocessable)
+			}
+		})
+	}
+}
+
+func TestLogForwarderUnreachableIsRetried(t *testing.T) {
+	logger := httptest.NewServer(http.NotFoundHandler())
+	url := logger.URL
+	logger.Close()
+
+	forwarder := NewLogForwarder(url, NewHTTPClient(time.Second, 1))
+	err := forwarder.Handle(context.Background(), Payload{Name: "event", Data: "data"})
+
+	if err == nil {
+		t.Fatal("Handle succeeded against a closed server")
+	}
+	if errors.Is(err, errUnprocessable) {
+		t.Fatalf("a transport error was unprocessable, want it retried: %v", err)
+	}
+}
+
+func TestLogForwarderPostsToConfiguredURL(t *testing.T) {
+	logger := newLoggerStandIn(t, http.StatusCreated)
+	forwarder := NewLogForwarder(logger.URL+"/custom/logs", NewHTTPClient(time.Second, 1))
+
+	payload := Payload{
+		Name:       "user.login",
+		Data:       "alice logged in",
+		Level:      "WARNING",
+		Service:    "auth",
+		Tags:       []string{"security"},
+		RoutingKey: "log.WARNING",
+	}
+	if err := forwarder.Handle(context.Background(), payload); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	if logger.method != http.MethodPost || logger.path != "/custom/logs" {
+		t.Fatalf("request was %s %s, want POST /custom/logs", logger.method, logger.path)
+	}
+	if got := logger.header.Get("Content-Type"); got != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", got)
+	}
+	got := logger.received
+	if got.Name != payload.Name || got.Data != payload.Data || got.Level != payload.Level || got.Service != payload.Service || len(got.Tags) != 1 {
+		t.Fatalf("logger received %+v, want %+v", got, payload)
+	}
+}
+
+func TestAuthAuditForwardsToAuditURL(t *testing.T) {
+	audit := newLoggerStandIn(t, http.StatusCreated)
+	handler := NewAuthAudit(NewLogForwarder(audit.URL+"/api/v1/audit", NewHTTPClient(time.Second, 1)))
+
+	err := handler.Handle(context.Background(), Payload{Name: "auth", Data: "login", RoutingKey: "auth.login"})
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	if audit.path != "/api/v1/audit" {
+		t.Fatalf("posted to %s, want /api/v1/audit", audit.path)
+	}
+	if audit.received.Name != "audit:auth.login" || audit.received.Level != "INFO" {
+		t.Fatalf("audit received %+v", audit.received)
+	}
+}
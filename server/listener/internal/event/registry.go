@@ -0,0 +1,120 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Handler processes one event. Returning an error wrapping errUnprocessable
+// dead-letters the message at once; any other error schedules a retry.
+type Handler interface {
+	Handle(ctx context.Context, payload Payload) error
+}
+
+type HandlerFunc func(ctx context.Context, payload Payload) error
+
+func (f HandlerFunc) Handle(ctx context.Context, payload Payload) error {
+	return f(ctx, payload)
+}
+
+type route struct {
+	pattern string
+	handler string
+}
+
+// Registry picks the handler for a delivery. A handler that claimed the
+// payload name wins, then routing-key routes in the order they were added,
+// then the default.
+type Registry struct {
+	handlers       map[string]Handler
+	names          map[string]string
+	routes         []route
+	defaultHandler string
+}
+
+func NewRegistry(defaultHandler string) *Registry {
+	return &Registry{
+		handlers:       make(map[string]Handler),
+		names:          make(map[string]string),
+		defaultHandler: defaultHandler,
+	}
+}
+
+// Register adds a handler under name and claims the given payload names
+// for it.
+func (r *Registry) Register(name string, handler Handler, payloadNames ...string) {
+	r.handlers[name] = handler
+	for _, payloadName := range payloadNames {
+		r.names[payloadName] = name
+	}
+}
+
+// Route sends deliveries whose routing key matches the AMQP topic pattern
+// to the named handler, which must already be registered.
+func (r *Registry) Route(pattern, handler string) error {
+	if _, ok := r.handlers[handler]; !ok {
+		return fmt.Errorf("route %s: no handler registered as %q", pattern, handler)
+	}
+
+	r.routes = append(r.routes, route{pattern: pattern, handler: handler})
+	return nil
+}
+
+func (r *Registry) Dispatch(ctx context.Context, payload Payload) error {
+	handler, ok := r.lookup(payload)
+	if !ok {
+		return fmt.Errorf("%w: no handler for routing key %q and name %q", errUnprocessable, payload.RoutingKey, payload.Name)
+	}
+
+	return handler.Handle(ctx, payload)
+}
+
+func (r *Registry) lookup(payload Payload) (Handler, bool) {
+	// Names are exact, so they are tried before the broad log.* style
+	// patterns that would otherwise swallow them.
+	if name, ok := r.names[payload.Name]; ok {
+		return r.handlers[name], true
+	}
+
+	for _, route := range r.routes {
+		if matchTopic(route.pattern, payload.RoutingKey) {
+			return r.handlers[route.handler], true
+		}
+	}
+
+	handler, ok := r.handlers[r.defaultHandler]
+	return handler, ok
+}
+
+// matchTopic reports whether key matches an AMQP topic pattern, where *
+// matches exactly one word and # matches zero or more.
+func matchTopic(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "#":
+			for i := 0; i <= len(key); i++ {
+				if matchWords(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case "*":
+			if len(key) == 0 {
+				return false
+			}
+		default:
+			if len(key) == 0 || key[0] != pattern[0] {
+				return false
+			}
+		}
+
+		pattern, key = pattern[1:], key[1:]
+	}
+
+	return len(key) == 0
+}
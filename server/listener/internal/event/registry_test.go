@@ -0,0 +1,63 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	registry := NewRegistry("log")
+
+	called := ""
+	handler := func(name string) Handler {
+		return HandlerFunc(func(context.Context, Payload) error {
+			called = name
+			return nil
+		})
+	}
+	registry.Register("log", handler("log"), "log", "event")
+	registry.Register("mail", handler("mail"), "mail")
+	registry.Register("audit", handler("audit"), "auth")
+
+	for _, route := range []struct{ pattern, handler string }{
+		{"log.*", "log"},
+		{"mail.#", "mail"},
+		{"auth.#", "audit"},
+	} {
+		if err := registry.Route(route.pattern, route.handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name, routingKey, want string
+	}{
+		{"mail", "log.INFO", "mail"},
+		{"auth", "log.WARNING", "audit"},
+		{"signup", "auth.user.created", "audit"},
+		{"welcome", "mail.send", "mail"},
+		{"unknown", "log.ERROR", "log"},
+		{"unknown", "orders.created", "log"},
+	}
+
+	for _, c := range cases {
+		called = ""
+		err := registry.Dispatch(context.Background(), Payload{Name: c.name, RoutingKey: c.routingKey})
+		if err != nil {
+			t.Fatalf("%s on %s: %v", c.name, c.routingKey, err)
+		}
+		if called != c.want {
+			t.Errorf("%s on %s went to %q, want %q", c.name, c.routingKey, called, c.want)
+		}
+	}
+}
+
+func TestRegistryNoHandler(t *testing.T) {
+	registry := NewRegistry("missing")
+
+	err := registry.Dispatch(context.Background(), Payload{Name: "x", RoutingKey: "log.INFO"})
+	if !errors.Is(err, errUnprocessable) {
+		t.Errorf("Dispatch with no handler returned %v, want errUnprocessable", err)
+	}
+}
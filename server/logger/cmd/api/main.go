@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -15,6 +17,7 @@ import (
 	"logger/internal/repositories"
 	"logger/internal/router"
 	"logger/internal/services"
+	"logger/types"
 )
 
 func main() {
@@ -24,37 +27,124 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize database
-	dbManager, err := database.NewManager(cfg.Database.URL, cfg.Database.Name)
+	// Initialize storage
+	store, err := openStorage(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer dbManager.Close()
+	defer store.close()
 
 	// Initialize dependencies
-	app, err := initializeApp(dbManager)
+	app, retention, err := initializeApp(cfg, store)
 	if err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
 
+	// Start the retention purger
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go retention.Run(ctx)
+
 	// Start server
 	if err := startServer(cfg, app); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
 
-func initializeApp(dbManager *database.Manager) (*router.App, error) {
-	// Initialize repository
-	logRepo := repositories.NewLogRepository(dbManager.GetDatabase())
+// storage holds the repositories on the backend selected by DB_DRIVER.
+type storage struct {
+	logs    types.LogRepositoryInterface
+	audit   types.AuditRepositoryInterface
+	history types.HistoryRepositoryInterface
+	close   func()
+}
+
+// openStorage connects the storage backend selected by DB_DRIVER. Its close
+// function releases the repositories.
+func openStorage(cfg config.DatabaseConfig) (*storage, error) {
+	switch cfg.Driver {
+	case config.DriverMemory:
+		log.Println("Storing logs in memory; they will be lost on restart")
+		return &storage{
+			logs:    repositories.NewMemoryRepository(),
+			audit:   repositories.NewMemoryAuditRepository(),
+			history: repositories.NewMemoryHistoryRepository(),
+			close:   func() {},
+		}, nil
+
+	case config.DriverFile:
+		var closers []io.Closer
+		closeAll := func() {
+			for _, c := range closers {
+				c.Close()
+			}
+		}
+
+		logRepo, err := repositories.NewFileRepository(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		closers = append(closers, logRepo.(io.Closer))
+
+		auditRepo, err := repositories.NewFileAuditRepository(cfg.AuditPath)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, auditRepo.(io.Closer))
+
+		historyRepo, err := repositories.NewFileHistoryRepository(cfg.HistoryPath)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		closers = append(closers, historyRepo.(io.Closer))
+
+		log.Printf("Storing logs in %s, the audit trail in %s and log history in %s", cfg.Path, cfg.AuditPath, cfg.HistoryPath)
+		return &storage{logs: logRepo, audit: auditRepo, history: historyRepo, close: closeAll}, nil
+
+	default:
+		dbManager, err := database.NewManager(cfg.URL, cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Connected to MongoDB successfully")
+		db := dbManager.GetDatabase()
+		return &storage{
+			logs:    repositories.NewLogRepository(db),
+			audit:   repositories.NewAuditRepository(db),
+			history: repositories.NewHistoryRepository(db),
+			close:   func() { dbManager.Close() },
+		}, nil
+	}
+}
+
+func initializeApp(cfg *config.Config, store *storage) (*router.App, *services.Retention, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := store.logs.EnsureIndexes(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log indexes: %w", err)
+	}
+	if err := store.history.EnsureIndexes(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to create history indexes: %w", err)
+	}
 
-	// Initialize service
-	logService := services.NewLogService(logRepo)
+	// Initialize services
+	logService := services.NewLogService(store.logs, store.history)
+	auditService := services.NewAuditService(store.audit)
+	retention := services.NewRetention(store.logs, store.history, cfg.Retention.Rules, services.RetentionOptions{
+		Interval:   cfg.Retention.Interval,
+		ArchiveDir: cfg.Retention.ArchiveDir,
+		TrashGrace: cfg.Retention.TrashGrace,
+	})
 
 	// Initialize handlers
 	logHandler := handlers.NewLogHandler(logService) // Fixed package name
+	auditHandler := handlers.NewAuditHandler(auditService)
 
 	// Create app with all handlers
-	return router.NewApp(logHandler), nil
+	return router.NewApp(logHandler, auditHandler), retention, nil
 }
 
 func startServer(cfg *config.Config, app *router.App) error {
@@ -69,7 +159,6 @@ func startServer(cfg *config.Config, app *router.App) error {
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Logger Service starting at: http://localhost:%s", cfg.Server.Port)
-		log.Println("Connected to MongoDB successfully")
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server failed to start: %v", err)
 		}
@@ -0,0 +1,209 @@
+// Command conformance runs the storage conformance suite against a backend:
+//
+//	go run ./cmd/conformance -driver memory
+//	go run ./cmd/conformance -driver file
+//	go run ./cmd/conformance -driver mongo -mongo-url mongodb://localhost:27017
+//
+// Each check gets an empty repository. For MongoDB that is a scratch
+// database which is dropped afterwards.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"logger/internal/config"
+	"logger/internal/db"
+	"logger/internal/repositories"
+	"logger/internal/repositories/conformance"
+	"logger/types"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func main() {
+	driver := flag.String("driver", config.DriverMemory, "backend to check: memory, file or mongo")
+	mongoURL := flag.String("mongo-url", "mongodb://localhost:27017", "MongoDB connection string")
+	dbName := flag.String("db", "logs_conformance", "prefix for the scratch MongoDB databases")
+	flag.Parse()
+
+	f, err := newFactories(*driver, *mongoURL, *dbName)
+	if err != nil {
+		log.Fatalf("Failed to set up %s backend: %v", *driver, err)
+	}
+
+	results := conformance.Run(context.Background(), f.logs)
+	results = append(results, conformance.RunAudit(context.Background(), f.audit)...)
+	results = append(results, conformance.RunHistory(context.Background(), f.history)...)
+	f.close()
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("FAIL  %-24s %v\n", result.Name, result.Err)
+			continue
+		}
+		fmt.Printf("PASS  %-24s %s\n", result.Name, result.Duration.Round(1e6))
+	}
+
+	fmt.Printf("%s: %d of %d checks passed\n", *driver, len(results)-failed, len(results))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// factories makes fresh repositories of each kind on one backend.
+type factories struct {
+	logs    conformance.Factory
+	audit   conformance.AuditFactory
+	history conformance.HistoryFactory
+	close   func()
+}
+
+func newFactories(driver, mongoURL, dbName string) (*factories, error) {
+	switch driver {
+	case config.DriverMemory:
+		return &factories{
+			logs: func(context.Context) (types.LogRepositoryInterface, func(), error) {
+				return repositories.NewMemoryRepository(), func() {}, nil
+			},
+			audit: func(context.Context) (types.AuditRepositoryInterface, func(), error) {
+				return repositories.NewMemoryAuditRepository(), func() {}, nil
+			},
+			history: func(context.Context) (types.HistoryRepositoryInterface, func(), error) {
+				return repositories.NewMemoryHistoryRepository(), func() {}, nil
+			},
+			close: func() {},
+		}, nil
+
+	case config.DriverFile:
+		dir, err := os.MkdirTemp("", "logger-conformance-")
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		scratch := func(kind string) string {
+			n++
+			return filepath.Join(dir, fmt.Sprintf("%s-%d.ndjson", kind, n))
+		}
+		return &factories{
+			logs: func(context.Context) (types.LogRepositoryInterface, func(), error) {
+				repo, err := repositories.NewFileRepository(scratch("check"))
+				if err != nil {
+					return nil, nil, err
+				}
+				return repo, func() { repo.(io.Closer).Close() }, nil
+			},
+			audit: func(context.Context) (types.AuditRepositoryInterface, func(), error) {
+				path := scratch("audit")
+				repo, err := repositories.NewFileAuditRepository(path)
+				if err != nil {
+					return nil, nil, err
+				}
+				// Read the entries back from disk rather than from memory.
+				return &reopened{AuditRepositoryInterface: repo, path: path}, func() { repo.(io.Closer).Close() }, nil
+			},
+			history: func(context.Context) (types.HistoryRepositoryInterface, func(), error) {
+				path := scratch("history")
+				repo, err := repositories.NewFileHistoryRepository(path)
+				if err != nil {
+					return nil, nil, err
+				}
+				return &reopenedHistory{HistoryRepositoryInterface: repo, path: path}, func() { repo.(io.Closer).Close() }, nil
+			},
+			close: func() { os.RemoveAll(dir) },
+		}, nil
+
+	case config.DriverMongo:
+		dbManager, err := database.NewManager(mongoURL, dbName)
+		if err != nil {
+			return nil, err
+		}
+		client := dbManager.GetDatabase().Client()
+		n := 0
+		scratch := func(ctx context.Context) (*mongo.Database, func(), error) {
+			n++
+			db := client.Database(fmt.Sprintf("%s_%d", dbName, n))
+			if err := db.Drop(ctx); err != nil {
+				return nil, nil, err
+			}
+			return db, func() { db.Drop(context.Background()) }, nil
+		}
+		return &factories{
+			logs: func(ctx context.Context) (types.LogRepositoryInterface, func(), error) {
+				db, drop, err := scratch(ctx)
+				if err != nil {
+					return nil, nil, err
+				}
+				return repositories.NewLogRepository(db), drop, nil
+			},
+			audit: func(ctx context.Context) (types.AuditRepositoryInterface, func(), error) {
+				db, drop, err := scratch(ctx)
+				if err != nil {
+					return nil, nil, err
+				}
+				return repositories.NewAuditRepository(db), drop, nil
+			},
+			history: func(ctx context.Context) (types.HistoryRepositoryInterface, func(), error) {
+				db, drop, err := scratch(ctx)
+				if err != nil {
+					return nil, nil, err
+				}
+				return repositories.NewHistoryRepository(db), drop, nil
+			},
+			close: func() { dbManager.Close() },
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown driver %q", driver)
+	}
+}
+
+// reopened serves FindBySeq from a fresh copy of the audit file, so checks
+// see what was persisted rather than what is cached in memory.
+type reopened struct {
+	types.AuditRepositoryInterface
+	path string
+}
+
+func (r *reopened) FindBySeq(ctx context.Context, seq int64) (*types.AuditEntry, error) {
+	repo, err := repositories.NewFileAuditRepository(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.(io.Closer).Close()
+
+	return repo.FindBySeq(ctx, seq)
+}
+
+// reopenedHistory serves lookups from a fresh copy of the history file.
+type reopenedHistory struct {
+	types.HistoryRepositoryInterface
+	path string
+}
+
+func (r *reopenedHistory) FindVersions(ctx context.Context, logID string) ([]types.LogVersion, error) {
+	repo, err := repositories.NewFileHistoryRepository(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.(io.Closer).Close()
+
+	return repo.FindVersions(ctx, logID)
+}
+
+func (r *reopenedHistory) FindVersion(ctx context.Context, logID string, version int) (*types.LogVersion, error) {
+	repo, err := repositories.NewFileHistoryRepository(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.(io.Closer).Close()
+
+	return repo.FindVersion(ctx, logID, version)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"logger/internal/config"
+	"logger/internal/repositories/conformance"
+)
+
+// TestConformance runs the suite against the memory and file backends.
+// MongoDB is checked too when LOGGER_TEST_MONGO_URL points at a server.
+func TestConformance(t *testing.T) {
+	drivers := []string{config.DriverMemory, config.DriverFile, config.DriverMongo}
+
+	for _, driver := range drivers {
+		t.Run(driver, func(t *testing.T) {
+			mongoURL := os.Getenv("LOGGER_TEST_MONGO_URL")
+			if driver == config.DriverMongo && mongoURL == "" {
+				t.Skip("LOGGER_TEST_MONGO_URL is not set")
+			}
+
+			f, err := newFactories(driver, mongoURL, "logs_conformance_test")
+			if err != nil {
+				t.Fatalf("setting up %s: %v", driver, err)
+			}
+			defer f.close()
+
+			ctx := context.Background()
+			results := conformance.Run(ctx, f.logs)
+			results = append(results, conformance.RunAudit(ctx, f.audit)...)
+			results = append(results, conformance.RunHistory(ctx, f.history)...)
+
+			for _, result := range results {
+				t.Run(result.Name, func(t *testing.T) {
+					if result.Err != nil {
+						t.Fatal(result.Err)
+					}
+				})
+			}
+		})
+	}
+}
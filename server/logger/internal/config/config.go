@@ -2,7 +2,11 @@ package config
 
 import (
 	"fmt"
+	"logger/types"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -10,7 +14,8 @@ import (
 
 type Config struct {
 	Server   ServerConfig
-	Database DatabaseConfig
+	Database  DatabaseConfig
+	Retention RetentionConfig
 }
 
 type ServerConfig struct {
@@ -20,9 +25,37 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration
 }
 
+// Storage backends selectable with DB_DRIVER.
+const (
+	DriverMongo  = "mongo"
+	DriverMemory = "memory"
+	DriverFile   = "file"
+)
+
+// DatabaseConfig selects the storage backend. URL and Name apply to mongo,
+// Path, AuditPath and HistoryPath to the file backend; memory keeps nothing
+// across restarts.
 type DatabaseConfig struct {
-	URL  string
-	Name string
+	Driver      string
+	URL         string
+	Name        string
+	Path        string
+	AuditPath   string
+	HistoryPath string
+}
+
+// RetentionConfig is read from LOG_RETENTION, a comma-separated list of
+// selector=age pairs such as "name:audit=365d,level:DEBUG=7d,*=90d". A
+// selector is name:<name>, level:<level> or * for everything else; ages are
+// Go durations or a whole number of days.
+//
+// LOG_TRASH_GRACE, in the same age format, is how long deleted entries stay
+// restorable before the purger removes them for good.
+type RetentionConfig struct {
+	Rules      []types.RetentionRule
+	Interval   time.Duration
+	ArchiveDir string
+	TrashGrace time.Duration
 }
 
 func Load() (*Config, error) {
@@ -36,11 +69,32 @@ func Load() (*Config, error) {
 			IdleTimeout:  getDurationWithDefault("IDLE_TIMEOUT", 60*time.Second),
 		},
 		Database: DatabaseConfig{
-			URL:  getEnvWithDefault("MONGO_URL", "mongodb://localhost:27017"),
-			Name: getEnvWithDefault("DB_NAME", "logs"),
+			Driver:      strings.ToLower(getEnvWithDefault("DB_DRIVER", DriverMongo)),
+			URL:         getEnvWithDefault("MONGO_URL", "mongodb://localhost:27017"),
+			Name:        getEnvWithDefault("DB_NAME", "logs"),
+			Path:        getEnvWithDefault("DB_PATH", "data/logs.ndjson"),
+			AuditPath:   getEnvWithDefault("DB_AUDIT_PATH", "data/audit.ndjson"),
+			HistoryPath: getEnvWithDefault("DB_HISTORY_PATH", "data/history.ndjson"),
+		},
+		Retention: RetentionConfig{
+			Interval:   getDurationWithDefault("LOG_RETENTION_INTERVAL", time.Hour),
+			ArchiveDir: os.Getenv("LOG_ARCHIVE_DIR"),
 		},
 	}
 
+	rules, err := parseRetention(os.Getenv("LOG_RETENTION"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_RETENTION: %w", err)
+	}
+	cfg.Retention.Rules = rules
+
+	cfg.Retention.TrashGrace = types.DefaultTrashGrace
+	if grace := os.Getenv("LOG_TRASH_GRACE"); grace != "" {
+		if cfg.Retention.TrashGrace, err = parseAge(grace); err != nil {
+			return nil, fmt.Errorf("invalid LOG_TRASH_GRACE: %w", err)
+		}
+	}
+
 	if err := cfg.validate(); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
 	}
@@ -49,11 +103,21 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) validate() error {
-	if c.Database.URL == "" {
-		return fmt.Errorf("database URL is required")
-	}
-	if c.Database.Name == "" {
-		return fmt.Errorf("database name is required")
+	switch c.Database.Driver {
+	case DriverMongo:
+		if c.Database.URL == "" {
+			return fmt.Errorf("database URL is required")
+		}
+		if c.Database.Name == "" {
+			return fmt.Errorf("database name is required")
+		}
+	case DriverFile:
+		if c.Database.Path == "" || c.Database.AuditPath == "" || c.Database.HistoryPath == "" {
+			return fmt.Errorf("database, audit and history paths are required for the file driver")
+		}
+	case DriverMemory:
+	default:
+		return fmt.Errorf("unknown database driver %q, use %s, %s or %s", c.Database.Driver, DriverMongo, DriverFile, DriverMemory)
 	}
 	return nil
 }
@@ -72,4 +136,73 @@ func getDurationWithDefault(key string, defaultValue time.Duration) time.Duratio
 		}
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+func parseRetention(value string) ([]types.RetentionRule, error) {
+	var rules []types.RetentionRule
+	seen := map[string]bool{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		selector, age, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q is not selector=age", entry)
+		}
+
+		maxAge, err := parseAge(strings.TrimSpace(age))
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+
+		rule := types.RetentionRule{MaxAge: maxAge}
+		selector = strings.TrimSpace(selector)
+
+		switch kind, target, _ := strings.Cut(selector, ":"); {
+		case selector == "*":
+		case kind == "name" && target != "":
+			rule.Name = target
+		case kind == "level" && target != "":
+			rule.Level = strings.ToUpper(target)
+			if !slices.Contains(types.LogLevels, rule.Level) {
+				return nil, fmt.Errorf("%q: %w %q", entry, types.ErrInvalidLogLevel, target)
+			}
+		default:
+			return nil, fmt.Errorf("%q: selector must be name:<name>, level:<level> or *", entry)
+		}
+
+		if seen[rule.String()] {
+			return nil, fmt.Errorf("%q: duplicate rule", entry)
+		}
+		seen[rule.String()] = true
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parseAge(value string) (time.Duration, error) {
+	var age time.Duration
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if age, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid age %q", value)
+		}
+	}
+
+	if age <= 0 {
+		return 0, fmt.Errorf("age %q must be positive", value)
+	}
+
+	return age, nil
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"logger/internal/helpers"
+	"logger/types"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type AuditHandler struct {
+	auditService types.AuditServiceInterface
+}
+
+func NewAuditHandler(auditService types.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// CreateAuditEntry appends to the audit trail. The body is the same as for
+// creating a log entry.
+func (h *AuditHandler) CreateAuditEntry(w http.ResponseWriter, r *http.Request) {
+	var req types.CreateLogRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", types.ErrInvalidBody, err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	entry, err := h.auditService.AppendAudit(ctx, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Audit entry recorded successfully",
+		Data:    entry,
+	}
+
+	helpers.WriteJSON(w, http.StatusCreated, payload)
+}
+
+// GetAuditEntries lists the trail in order. Query parameters: after, the
+// seq of the last entry already seen, and limit.
+func (h *AuditHandler) GetAuditEntries(w http.ResponseWriter, r *http.Request) {
+	var query types.AuditQuery
+	values := r.URL.Query()
+
+	if after := values.Get("after"); after != "" {
+		n, err := strconv.ParseInt(after, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, r, fmt.Errorf("%w: after must be a sequence number", types.ErrInvalidQuery))
+			return
+		}
+		query.After = n
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			writeError(w, r, fmt.Errorf("%w: limit must be a positive integer", types.ErrInvalidQuery))
+			return
+		}
+		query.Limit = n
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	page, err := h.auditService.ListAudit(ctx, query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Audit entries retrieved successfully",
+		Data:    page,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+func (h *AuditHandler) GetAuditEntry(w http.ResponseWriter, r *http.Request) {
+	seq, err := strconv.ParseInt(mux.Vars(r)["seq"], 10, 64)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: audit entries are addressed by sequence number", types.ErrInvalidQuery))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	entry, err := h.auditService.GetAuditEntry(ctx, seq)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Audit entry retrieved successfully",
+		Data:    entry,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+// RejectAuditChange answers every attempt to update or delete audit
+// entries.
+func (h *AuditHandler) RejectAuditChange(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", "GET")
+	writeError(w, r, fmt.Errorf("%s is not allowed: %w", r.Method, types.ErrAuditImmutable))
+}
+
+// VerifyAudit walks the whole chain. It responds 200 when the chain holds
+// and 409 with the first break when it does not.
+func (h *AuditHandler) VerifyAudit(w http.ResponseWriter, r *http.Request) {
+	// The walk reads every entry, which can outlast the write timeout.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	defer cancel()
+
+	result, err := h.auditService.VerifyAudit(ctx)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if !result.Valid {
+		problem := newProblem(r, fmt.Errorf("%w at entry %d: %s", types.ErrAuditChainBroken, result.Break.Seq, result.Break.Reason))
+		problem.Data = result
+		helpers.WriteProblem(w, problem.Status, problem)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: fmt.Sprintf("Audit chain verified through entry %d", result.HeadSeq),
+		Data:    result,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
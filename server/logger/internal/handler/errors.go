@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"logger/internal/helpers"
+	"logger/types"
+	"net/http"
+)
+
+// problemType is how one domain error is reported.
+type problemType struct {
+	err    error
+	status int
+	code   string
+	title  string
+}
+
+// problemTypes maps domain errors to responses; the first match wins.
+// Codes are part of the API and must not change once published.
+var problemTypes = []problemType{
+	{types.ErrLogIDRequired, http.StatusBadRequest, "log_id_required", "Log ID is required"},
+	{types.ErrInvalidLogID, http.StatusBadRequest, "invalid_log_id", "Invalid log ID"},
+	{types.ErrActorRequired, http.StatusBadRequest, "actor_required", "Actor required"},
+	{types.ErrLogNotFound, http.StatusNotFound, "log_not_found", "Log entry not found"},
+	{types.ErrLogVersionNotFound, http.StatusNotFound, "log_version_not_found", "Log version not found"},
+	{types.ErrSnapshotNotFound, http.StatusNotFound, "snapshot_not_found", "Trash snapshot not found"},
+	{types.ErrInvalidBody, http.StatusBadRequest, "invalid_body", "Invalid request body"},
+	{types.ErrInvalidLog, http.StatusBadRequest, "invalid_log", "Invalid log entry"},
+	{types.ErrInvalidLogLevel, http.StatusBadRequest, "invalid_log_level", "Invalid log level"},
+	{types.ErrInvalidQuery, http.StatusBadRequest, "invalid_query", "Invalid query parameters"},
+	{types.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor", "Invalid cursor"},
+	{types.ErrConfirmationRequired, http.StatusBadRequest, "confirmation_required", "Confirmation required"},
+	{types.ErrBulkTooLarge, http.StatusRequestEntityTooLarge, "bulk_too_large", "Too many log entries"},
+	{types.ErrLogConflict, http.StatusConflict, "log_conflict", "Log entry was modified concurrently"},
+	{types.ErrAuditNotFound, http.StatusNotFound, "audit_entry_not_found", "Audit entry not found"},
+	{types.ErrAuditImmutable, http.StatusMethodNotAllowed, "audit_immutable", "Audit entries are immutable"},
+	{types.ErrAuditChainBroken, http.StatusConflict, "audit_chain_broken", "Audit chain is broken"},
+	{types.ErrAuditConflict, http.StatusServiceUnavailable, "audit_busy", "Audit trail is busy"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout", "Request timed out"},
+}
+
+var internalProblem = problemType{
+	status: http.StatusInternalServerError,
+	code:   "internal",
+	title:  "Internal server error",
+}
+
+// internalDetail replaces the detail of unexpected errors, which can name
+// hosts, collections or file paths. The cause is logged instead.
+const internalDetail = "The server could not complete the request."
+
+// newProblem describes err, returned while serving r, as a problem.
+func newProblem(r *http.Request, err error) *types.Problem {
+	kind := internalProblem
+	for _, candidate := range problemTypes {
+		if errors.Is(err, candidate.err) {
+			kind = candidate
+			break
+		}
+	}
+
+	detail := err.Error()
+	if kind.code == internalProblem.code {
+		log.Printf("%s %s failed: %v", r.Method, r.URL.Path, err)
+		detail = internalDetail
+	}
+
+	return &types.Problem{
+		Type:     types.ProblemTypeBase + kind.code,
+		Title:    kind.title,
+		Status:   kind.status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     kind.code,
+	}
+}
+
+// writeError responds with err as an application/problem+json document.
+// Every handler reports errors through it, so an error gets the same
+// status and code wherever it surfaces.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := newProblem(r, err)
+	helpers.WriteProblem(w, problem.Status, problem)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"logger/types"
+)
+
+func TestNewProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/logs/abc", nil)
+
+	problem := newProblem(r, fmt.Errorf("%w: limit must be positive", types.ErrInvalidQuery))
+	if problem.Status != http.StatusBadRequest || problem.Code != "invalid_query" {
+		t.Errorf("invalid query became %d %s", problem.Status, problem.Code)
+	}
+	if !strings.Contains(problem.Detail, "limit must be positive") {
+		t.Errorf("client error detail = %q, want the cause", problem.Detail)
+	}
+
+	problem = newProblem(r, errors.New("dial tcp 10.0.0.5:27017: connection refused"))
+	if problem.Status != http.StatusInternalServerError || problem.Code != "internal" {
+		t.Errorf("unexpected error became %d %s", problem.Status, problem.Code)
+	}
+	if problem.Detail != internalDetail {
+		t.Errorf("internal error detail = %q, want it hidden", problem.Detail)
+	}
+	if problem.Instance != "/api/v1/logs/abc" {
+		t.Errorf("instance = %q", problem.Instance)
+	}
+}
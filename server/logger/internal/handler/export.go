@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"logger/internal/helpers"
+	"logger/types"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// importBatchSize is how many entries are written to the database at
+	// a time while importing.
+	importBatchSize = 500
+	// maxImportLine matches MongoDB's document size limit.
+	maxImportLine = 16 << 20
+)
+
+var csvHeader = []string{
+	"id", "name", "level", "service", "host", "request_id", "trace_id",
+	"tags", "attributes", "created_at", "updated_at", "data",
+}
+
+// ExportLogs streams every log matching the list filters, oldest first, as
+// NDJSON (the default) or CSV (format=csv). With gzip=true the download is
+// gzip-compressed. Entries are written as they are read from the database,
+// so exports of any size use constant memory.
+func (h *LogHandler) ExportLogs(w http.ResponseWriter, r *http.Request) {
+	query, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	format := types.ExportFormat(strings.ToLower(r.URL.Query().Get("format")))
+	switch format {
+	case "":
+		format = types.ExportNDJSON
+	case types.ExportNDJSON, types.ExportCSV:
+	default:
+		writeError(w, r, fmt.Errorf("%w: format must be %q or %q", types.ErrInvalidQuery, types.ExportNDJSON, types.ExportCSV))
+		return
+	}
+	compress := r.URL.Query().Get("gzip") == "true"
+
+	// Large exports outlast the server's write timeout.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	contentType := "application/x-ndjson"
+	if format == types.ExportCSV {
+		contentType = "text/csv"
+	}
+	filename := fmt.Sprintf("logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	if compress {
+		contentType = "application/gzip"
+		filename += ".gz"
+	}
+
+	// Headers are only sent with the first entry so a failure before then,
+	// such as an invalid level, can still be reported as JSON.
+	buffered := bufio.NewWriter(w)
+	var (
+		gz         *gzip.Writer
+		csvWriter  *csv.Writer
+		encoder    *json.Encoder
+		writeEntry func(types.Log) error
+	)
+
+	start := func() {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		w.WriteHeader(http.StatusOK)
+
+		var out io.Writer = buffered
+		if compress {
+			gz = gzip.NewWriter(buffered)
+			out = gz
+		}
+
+		if format == types.ExportCSV {
+			csvWriter = csv.NewWriter(out)
+			csvWriter.Write(csvHeader)
+			writeEntry = func(entry types.Log) error {
+				data, err := json.Marshal(entry.Data)
+				if err != nil {
+					return err
+				}
+				attributes := ""
+				if len(entry.Attributes) > 0 {
+					encoded, err := json.Marshal(entry.Attributes)
+					if err != nil {
+						return err
+					}
+					attributes = string(encoded)
+				}
+				csvWriter.Write([]string{
+					entry.ID,
+					entry.Name,
+					entry.Level,
+					entry.Service,
+					entry.Host,
+					entry.RequestID,
+					entry.TraceID,
+					strings.Join(entry.Tags, ";"),
+					attributes,
+					entry.CreatedAt.Format(time.RFC3339Nano),
+					entry.UpdatedAt.Format(time.RFC3339Nano),
+					string(data),
+				})
+				return csvWriter.Error()
+			}
+			return
+		}
+
+		encoder = json.NewEncoder(out)
+		writeEntry = func(entry types.Log) error {
+			return encoder.Encode(entry)
+		}
+	}
+
+	ctx := r.Context()
+	exported := 0
+
+	err = h.logService.ExportLogs(ctx, query.LogFilter, func(entry types.Log) error {
+		if exported == 0 {
+			start()
+		}
+		exported++
+		return writeEntry(entry)
+	})
+	if err != nil && exported == 0 {
+		writeError(w, r, err)
+		return
+	}
+	if err != nil {
+		// The status line is already sent; cutting the body short is the
+		// only way left to signal the failure.
+		log.Printf("Export failed after %d logs: %v", exported, err)
+		return
+	}
+
+	if exported == 0 {
+		start()
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+	}
+	if gz != nil {
+		gz.Close()
+	}
+	buffered.Flush()
+}
+
+// ImportLogs restores an NDJSON export, gzip-compressed when sent with
+// Content-Encoding: gzip. IDs and timestamps are kept, and entries whose ID
+// already exists are skipped, so re-running an import is harmless.
+func (h *LogHandler) ImportLogs(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	if strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: %v", types.ErrInvalidBody, err))
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Minute)
+	defer cancel()
+
+	result := &types.ImportResult{}
+	batch := make([]types.Log, 0, importBatchSize)
+	lines := make([]int, 0, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		batchResult, err := h.logService.ImportLogs(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for i := range batchResult.Errors {
+			batchResult.Errors[i].Line = lines[batchResult.Errors[i].Line-1]
+		}
+		result.Add(batchResult)
+
+		batch = batch[:0]
+		lines = lines[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLine)
+
+	var err error
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var entry types.Log
+		if jsonErr := json.Unmarshal(data, &entry); jsonErr != nil {
+			result.Add(&types.ImportResult{
+				Invalid: 1,
+				Errors:  []types.ImportError{{Line: line, Error: "invalid JSON: " + jsonErr.Error()}},
+			})
+			continue
+		}
+
+		batch = append(batch, entry)
+		lines = append(lines, line)
+
+		if len(batch) == importBatchSize {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil && scanner.Err() != nil {
+		// A line too long or a corrupt gzip stream; the body is at fault.
+		err = fmt.Errorf("%w: %v", types.ErrInvalidBody, scanner.Err())
+	}
+	if err == nil {
+		err = flush()
+	}
+
+	if err != nil {
+		problem := newProblem(r, fmt.Errorf("import stopped after %d logs: %w", result.Imported, err))
+		problem.Data = result
+		helpers.WriteProblem(w, problem.Status, problem)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if result.Invalid > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: fmt.Sprintf("Imported %d logs", result.Imported),
+		Data:    result,
+	}
+
+	helpers.WriteJSON(w, statusCode, payload)
+}
@@ -1,7 +1,14 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"logger/internal/helpers"
 	"logger/types"
 	"net/http"
@@ -42,8 +49,18 @@ func (h *LogHandler) Home(w http.ResponseWriter, r *http.Request) {
 			"version": "1.0.0",
 			"endpoints": map[string]string{
 				"health": "/health",
-				"logs":   "/api/v1/logs",
-				"stats":  "/api/v1/logs/stats",
+				"logs":      "/api/v1/logs",
+				"stats":     "/api/v1/logs/stats",
+				"search":    "/api/v1/logs/search",
+				"histogram": "/api/v1/logs/histogram",
+				"bulk":      "/api/v1/logs/bulk",
+				"stream":    "/api/v1/logs/stream",
+				"export":    "/api/v1/logs/export",
+				"import":    "/api/v1/logs/import",
+				"trash":     "/api/v1/logs/trash",
+				"history":   "/api/v1/logs/{id}/history",
+				"audit":     "/api/v1/audit",
+				"verify":    "/api/v1/audit/verify",
 			},
 		},
 	}
@@ -51,25 +68,75 @@ func (h *LogHandler) Home(w http.ResponseWriter, r *http.Request) {
 	helpers.WriteJSON(w, http.StatusOK, payload)
 }
 
+// GetAllLogs lists logs a page at a time. Query parameters: limit, cursor,
+// sort (asc|desc), from and to (RFC 3339), name (repeatable or comma
+// separated), level and q for a substring search over data.
 func (h *LogHandler) GetAllLogs(w http.ResponseWriter, r *http.Request) {
+	query, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	logs, err := h.logService.GetAllLogs(ctx)
+	page, err := h.logService.GetLogs(ctx, query)
 	if err != nil {
-		payload := types.JsonResponse{
-			Success: false,
-			Message: "Failed to fetch logs",
-			Error:   err.Error(),
-		}
-		helpers.WriteJSON(w, http.StatusInternalServerError, payload)
+		writeError(w, r, err)
 		return
 	}
 
 	payload := types.JsonResponse{
 		Success: true,
 		Message: "Logs retrieved successfully",
-		Data:    logs,
+		Data:    page,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+// SearchLogs runs a full-text search over log names and data. Query
+// parameters: q (required), limit, offset, and the level, name, from and to
+// filters accepted by GetAllLogs.
+func (h *LogHandler) SearchLogs(w http.ResponseWriter, r *http.Request) {
+	logQuery, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// q is the search text here, not the substring filter it is for
+	// listing.
+	filter := logQuery.LogFilter
+	filter.Search = ""
+
+	query := types.SearchQuery{
+		LogFilter: filter,
+		Text:      logQuery.Search,
+		Limit:     logQuery.Limit,
+	}
+
+	if offset := r.URL.Query().Get("offset"); offset != "" {
+		if query.Offset, err = strconv.Atoi(offset); err != nil {
+			writeError(w, r, fmt.Errorf("%w: offset must be an integer", types.ErrInvalidQuery))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	result, err := h.logService.SearchLogs(ctx, query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Search completed successfully",
+		Data:    result,
 	}
 
 	helpers.WriteJSON(w, http.StatusOK, payload)
@@ -84,18 +151,7 @@ func (h *LogHandler) GetLogByID(w http.ResponseWriter, r *http.Request) {
 
 	logEntry, err := h.logService.GetLogByID(ctx, id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "log not found" {
-			statusCode = http.StatusNotFound
-		} else if err.Error() == "log ID is required" {
-			statusCode = http.StatusBadRequest
-		}
-
-		payload := types.JsonResponse{
-			Success: false,
-			Message: err.Error(),
-		}
-		helpers.WriteJSON(w, statusCode, payload)
+		writeError(w, r, err)
 		return
 	}
 
@@ -113,12 +169,7 @@ func (h *LogHandler) CreateLog(w http.ResponseWriter, r *http.Request) {
 
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		payload := types.JsonResponse{
-			Success: false,
-			Message: "Invalid JSON format",
-			Error:   err.Error(),
-		}
-		helpers.WriteJSON(w, http.StatusBadRequest, payload)
+		writeError(w, r, fmt.Errorf("%w: %v", types.ErrInvalidBody, err))
 		return
 	}
 
@@ -127,16 +178,7 @@ func (h *LogHandler) CreateLog(w http.ResponseWriter, r *http.Request) {
 
 	createdLog, err := h.logService.CreateLog(ctx, req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "name field is required" || err.Error() == "data field is required" {
-			statusCode = http.StatusBadRequest
-		}
-
-		payload := types.JsonResponse{
-			Success: false,
-			Message: err.Error(),
-		}
-		helpers.WriteJSON(w, statusCode, payload)
+		writeError(w, r, err)
 		return
 	}
 
@@ -149,6 +191,105 @@ func (h *LogHandler) CreateLog(w http.ResponseWriter, r *http.Request) {
 	helpers.WriteJSON(w, http.StatusCreated, payload)
 }
 
+// maxBulkBytes bounds the body of a bulk request.
+const maxBulkBytes = 16 << 20
+
+// CreateLogs stores a batch of entries sent either as a JSON array or, with
+// Content-Type application/x-ndjson, one JSON object per line. It responds
+// 201 when every entry was stored, 207 when only some were, and reports
+// each entry's outcome in order.
+func (h *LogHandler) CreateLogs(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkBytes)
+
+	var (
+		reqs    []types.CreateLogRequest
+		invalid map[int]string
+		err     error
+	)
+
+	mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "application/x-ndjson", "application/ndjson":
+		reqs, invalid, err = readNDJSON(r.Body)
+	default:
+		err = json.NewDecoder(r.Body).Decode(&reqs)
+	}
+	if err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", types.ErrInvalidBody, err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.logService.CreateLogs(ctx, reqs)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Lines that were not valid JSON were sent to the service as empty
+	// requests; report the parse error instead of the validation error.
+	for index, message := range invalid {
+		result.Items[index].Error = message
+	}
+
+	if result.Created == 0 {
+		// Every entry was rejected; items say why. Storage failures
+		// outrank invalid entries.
+		err := fmt.Errorf("%w: no log entries were created", types.ErrInvalidLog)
+		if result.Failed > 0 {
+			err = errors.New("no log entries were created")
+		}
+		problem := newProblem(r, err)
+		problem.Data = result
+		helpers.WriteProblem(w, problem.Status, problem)
+		return
+	}
+
+	statusCode := http.StatusCreated
+	message := "Log entries created successfully"
+	if result.Invalid > 0 || result.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+		message = fmt.Sprintf("Created %d of %d log entries", result.Created, len(result.Items))
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: message,
+		Data:    result,
+	}
+
+	helpers.WriteJSON(w, statusCode, payload)
+}
+
+// readNDJSON decodes one entry per non-blank line. A line that is not valid
+// JSON keeps its position as an empty entry, which the service rejects, and
+// its parse error is returned by index.
+func readNDJSON(body io.Reader) ([]types.CreateLogRequest, map[int]string, error) {
+	var reqs []types.CreateLogRequest
+	invalid := map[int]string{}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxBulkBytes)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req types.CreateLogRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			invalid[len(reqs)] = "invalid JSON: " + err.Error()
+			req = types.CreateLogRequest{}
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, invalid, scanner.Err()
+}
+
 func (h *LogHandler) UpdateLog(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -156,32 +297,17 @@ func (h *LogHandler) UpdateLog(w http.ResponseWriter, r *http.Request) {
 	var req types.UpdateLogRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		payload := types.JsonResponse{
-			Success: false,
-			Message: "Invalid JSON format",
-			Error:   err.Error(),
-		}
-		helpers.WriteJSON(w, http.StatusBadRequest, payload)
+		writeError(w, r, fmt.Errorf("%w: %v", types.ErrInvalidBody, err))
 		return
 	}
+	req.ChangedBy = r.Header.Get("X-Actor")
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
 	updatedLog, err := h.logService.UpdateLog(ctx, id, req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "log not found" {
-			statusCode = http.StatusNotFound
-		} else if err.Error() == "log ID is required" {
-			statusCode = http.StatusBadRequest
-		}
-
-		payload := types.JsonResponse{
-			Success: false,
-			Message: err.Error(),
-		}
-		helpers.WriteJSON(w, statusCode, payload)
+		writeError(w, r, err)
 		return
 	}
 
@@ -203,71 +329,58 @@ func (h *LogHandler) DeleteLog(w http.ResponseWriter, r *http.Request) {
 
 	err := h.logService.DeleteLog(ctx, id)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "log not found" {
-			statusCode = http.StatusNotFound
-		} else if err.Error() == "log ID is required" {
-			statusCode = http.StatusBadRequest
-		}
-
-		payload := types.JsonResponse{
-			Success: false,
-			Message: err.Error(),
-		}
-		helpers.WriteJSON(w, statusCode, payload)
+		writeError(w, r, err)
 		return
 	}
 
 	payload := types.JsonResponse{
 		Success: true,
-		Message: "Log entry deleted successfully",
+		Message: "Log entry moved to trash",
 	}
 
 	helpers.WriteJSON(w, http.StatusOK, payload)
 }
 
+// DropAllLogs moves every entry to the trash as one snapshot, which can be
+// restored until the trash grace period purges it.
 func (h *LogHandler) DropAllLogs(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Query().Get("confirm") != "true" {
-		payload := types.JsonResponse{
-			Success: false,
-			Message: "This operation requires confirmation. Add query parameter 'confirm=true'",
-		}
-		helpers.WriteJSON(w, http.StatusBadRequest, payload)
+		writeError(w, r, fmt.Errorf("%w: add query parameter 'confirm=true'", types.ErrConfirmationRequired))
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	err := h.logService.DropAllLogs(ctx)
+	snapshot, err := h.logService.DropAllLogs(ctx)
 	if err != nil {
-		payload := types.JsonResponse{
-			Success: false,
-			Message: err.Error(),
-		}
-		helpers.WriteJSON(w, http.StatusInternalServerError, payload)
+		writeError(w, r, err)
 		return
 	}
 
 	payload := types.JsonResponse{
 		Success: true,
-		Message: "All logs have been deleted successfully",
+		Message: fmt.Sprintf("Moved %d logs to trash; restore them with POST /api/v1/logs/trash/restore?snapshot=%s", snapshot.Count, snapshot.ID),
+		Data:    snapshot,
 	}
 
 	helpers.WriteJSON(w, http.StatusOK, payload)
 }
 
+// GetLogsStats accepts the level, name, from and to filters of GetAllLogs.
 func (h *LogHandler) GetLogsStats(w http.ResponseWriter, r *http.Request) {
+	query, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	stats, err := h.logService.GetLogStats(ctx)
+	stats, err := h.logService.GetLogStats(ctx, query.LogFilter)
 	if err != nil {
-		payload := types.JsonResponse{
-			Success: false,
-			Message: err.Error(),
-		}
-		helpers.WriteJSON(w, http.StatusInternalServerError, payload)
+		writeError(w, r, err)
 		return
 	}
 
@@ -278,4 +391,93 @@ func (h *LogHandler) GetLogsStats(w http.ResponseWriter, r *http.Request) {
 	}
 
 	helpers.WriteJSON(w, http.StatusOK, payload)
-}
\ No newline at end of file
+}
+
+// GetLogsHistogram counts logs per bucket (minute, hour or day) between
+// from and to, with the same filters as GetAllLogs.
+func (h *LogHandler) GetLogsHistogram(w http.ResponseWriter, r *http.Request) {
+	logQuery, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	query := types.HistogramQuery{
+		LogFilter: logQuery.LogFilter,
+		Bucket:    types.BucketSize(strings.ToLower(r.URL.Query().Get("bucket"))),
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	histogram, err := h.logService.GetLogHistogram(ctx, query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Log histogram retrieved successfully",
+		Data:    histogram,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+func parseLogQuery(r *http.Request) (types.LogQuery, error) {
+	values := r.URL.Query()
+
+	query := types.LogQuery{
+		LogFilter: types.LogFilter{
+			Level:     values.Get("level"),
+			Search:    values.Get("q"),
+			Service:   values.Get("service"),
+			RequestID: values.Get("request_id"),
+			TraceID:   values.Get("trace_id"),
+		},
+		Cursor: values.Get("cursor"),
+		Sort:   types.SortDirection(strings.ToLower(values.Get("sort"))),
+	}
+
+	query.Names = splitList(values["name"])
+	query.Tags = splitList(values["tag"])
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, fmt.Errorf("%w: limit must be a positive integer", types.ErrInvalidQuery)
+		}
+		query.Limit = n
+	}
+
+	var err error
+	if query.From, err = parseTime(values.Get("from")); err != nil {
+		return query, fmt.Errorf("%w: from: %v", types.ErrInvalidQuery, err)
+	}
+	if query.To, err = parseTime(values.Get("to")); err != nil {
+		return query, fmt.Errorf("%w: to: %v", types.ErrInvalidQuery, err)
+	}
+
+	return query, nil
+}
+
+// splitList accepts a parameter repeated, comma-separated, or both.
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"logger/internal/helpers"
+	"logger/types"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// GetLogHistory lists the earlier versions of an entry, oldest first, each
+// with who replaced it, when, and the JSON Patch that led to the next one.
+func (h *LogHandler) GetLogHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	history, err := h.logService.GetLogHistory(ctx, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Log history retrieved successfully",
+		Data:    history,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+// RevertLog restores an entry to an earlier version, given as
+// {"version": N} or ?version=N. The revert is itself an update, so the
+// version it replaces stays in the history. Like UpdateLog, it refuses a
+// request without an X-Actor header naming who made the change.
+func (h *LogHandler) RevertLog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		Version int `json:"version"`
+	}
+	if v := r.URL.Query().Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, r, fmt.Errorf("%w: version must be an integer", types.ErrInvalidQuery))
+			return
+		}
+		req.Version = n
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", types.ErrInvalidBody, err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	revertedLog, err := h.logService.RevertLog(ctx, id, req.Version, r.Header.Get("X-Actor"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Log entry reverted successfully",
+		Data:    revertedLog,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"logger/types"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// streamBuffer is how many entries may queue for a slow subscriber
+	// before the tail blocks.
+	streamBuffer = 64
+
+	sseHeartbeat = 15 * time.Second
+	wsPingPeriod = 30 * time.Second
+	wsPongWait   = 2 * wsPingPeriod
+	wsWriteWait  = 10 * time.Second
+)
+
+// The CORS middleware already allows every origin, so the upgrade does too.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// StreamLogs pushes new entries as they are written, over WebSocket when
+// the request asks for an upgrade and Server-Sent Events otherwise. It
+// accepts the filters of GetAllLogs, and after (or the Last-Event-ID header
+// on an SSE reconnect) resumes after the given log ID.
+func (h *LogHandler) StreamLogs(w http.ResponseWriter, r *http.Request) {
+	logQuery, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	query := types.TailQuery{
+		LogFilter: logQuery.LogFilter,
+		AfterID:   r.URL.Query().Get("after"),
+	}
+	if query.AfterID == "" {
+		query.AfterID = r.Header.Get("Last-Event-ID")
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		h.streamWebSocket(w, r, query)
+		return
+	}
+	h.streamSSE(w, r, query)
+}
+
+// tail runs the tail in the background. The entries channel closes when
+// the tail stops, after which errc holds its error.
+func (h *LogHandler) tail(ctx context.Context, query types.TailQuery) (<-chan types.Log, <-chan error) {
+	entries := make(chan types.Log, streamBuffer)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(entries)
+		errc <- h.logService.TailLogs(ctx, query, func(entry types.Log) error {
+			select {
+			case entries <- entry:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return entries, errc
+}
+
+func (h *LogHandler) streamSSE(w http.ResponseWriter, r *http.Request, query types.TailQuery) {
+	rc := http.NewResponseController(w)
+
+	// The server's write timeout would otherwise end the stream.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	entries, errc := h.tail(ctx, query)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				if err := <-errc; err != nil && ctx.Err() == nil {
+					data, _ := json.Marshal(map[string]string{"error": tailError(err)})
+					fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+					rc.Flush()
+				}
+				return
+			}
+
+			data, err := json.Marshal(entry)
+			if err != nil {
+				log.Printf("Failed to encode streamed log %s: %v", entry.ID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: log\ndata: %s\n\n", entry.ID, data); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamMessage is the envelope of every WebSocket frame.
+type streamMessage struct {
+	Type  string     `json:"type"`
+	Log   *types.Log `json:"log,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+func (h *LogHandler) streamWebSocket(w http.ResponseWriter, r *http.Request, query types.TailQuery) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the error response.
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// The hijacked connection keeps the server's deadlines; replace them
+	// with the keepalive ones below.
+	conn.NetConn().SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Clients only send control frames. Reading is what processes pongs
+	// and notices the client going away.
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	entries, errc := h.tail(ctx, query)
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				if err := <-errc; err != nil && ctx.Err() == nil {
+					conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+					conn.WriteJSON(streamMessage{Type: "error", Error: tailError(err)})
+				}
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsWriteWait))
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteJSON(streamMessage{Type: "log", Log: &entry}); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func tailError(err error) string {
+	if errors.Is(err, types.ErrInvalidCursor) {
+		return "unknown log id to resume after"
+	}
+	return err.Error()
+}
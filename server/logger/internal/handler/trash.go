@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"logger/internal/helpers"
+	"logger/types"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTrash lists deleted entries a page at a time. It accepts the query
+// parameters of GetAllLogs, plus snapshot to list one DropAllLogs batch.
+func (h *LogHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	query, err := parseLogQuery(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query.Snapshot = r.URL.Query().Get("snapshot")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	page, err := h.logService.GetTrash(ctx, query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Trash retrieved successfully",
+		Data:    page,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+func (h *LogHandler) RestoreLog(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	restoredLog, err := h.logService.RestoreLog(ctx, id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: "Log entry restored successfully",
+		Data:    restoredLog,
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
+
+// RestoreSnapshot restores every entry trashed by one DropAllLogs call.
+// Query parameter: snapshot, the ID DropAllLogs returned.
+func (h *LogHandler) RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	snapshot := r.URL.Query().Get("snapshot")
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	restored, err := h.logService.RestoreSnapshot(ctx, snapshot)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if restored == 0 {
+		writeError(w, r, fmt.Errorf("%w %q", types.ErrSnapshotNotFound, snapshot))
+		return
+	}
+
+	payload := types.JsonResponse{
+		Success: true,
+		Message: fmt.Sprintf("Restored %d logs", restored),
+		Data:    map[string]int64{"restored": restored},
+	}
+
+	helpers.WriteJSON(w, http.StatusOK, payload)
+}
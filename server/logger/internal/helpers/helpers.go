@@ -31,6 +31,15 @@ func ReadJSON(w http.ResponseWriter, r *http.Request, data any) error {
 }
 
 func WriteJSON(w http.ResponseWriter, status int, data any, headers ...http.Header) error {
+	return write(w, status, "application/json", data, headers...)
+}
+
+// WriteProblem writes an RFC 7807 problem details document.
+func WriteProblem(w http.ResponseWriter, status int, problem any, headers ...http.Header) error {
+	return write(w, status, "application/problem+json", problem, headers...)
+}
+
+func write(w http.ResponseWriter, status int, contentType string, data any, headers ...http.Header) error {
 	
 	out, err := json.Marshal(data)
 	
@@ -44,7 +53,7 @@ func WriteJSON(w http.ResponseWriter, status int, data any, headers ...http.Head
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(status)
 
 	_, err = w.Write(out)
@@ -1,7 +1,12 @@
 package middleware
 
 import (
+	"bufio"
+	"errors"
 	"log"
+	"logger/internal/helpers"
+	"logger/types"
+	"net"
 	"net/http"
 	"runtime/debug"
 	"time"
@@ -40,7 +45,13 @@ func Recovery() func(http.Handler) http.Handler {
 			defer func() {
 				if err := recover(); err != nil {
 					log.Printf("Panic: %v\n%s", err, debug.Stack())
-					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+					helpers.WriteProblem(w, http.StatusInternalServerError, types.Problem{
+						Type:     types.ProblemTypeBase + "internal",
+						Title:    "Internal server error",
+						Status:   http.StatusInternalServerError,
+						Instance: r.URL.Path,
+						Code:     "internal",
+					})
 				}
 			}()
 			next.ServeHTTP(w, r)
@@ -56,4 +67,27 @@ type responseWriter struct {
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, which
+// streaming handlers use to flush and to lift the write deadline.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack is needed for WebSocket upgrades, which check for http.Hijacker
+// directly rather than through a ResponseController.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
 }
\ No newline at end of file
@@ -52,7 +52,7 @@ func (m *LogEntryModel) All() ([]*Log, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
-	opts := options.Find().SetSort(bson.D{{"created_at", -1}})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 	cursor, err := m.Collection.Find(ctx, bson.D{}, opts)
 	if err != nil {
 		log.Println("Find error:", err)
@@ -104,10 +104,10 @@ func (m *LogEntryModel) Update(entry *Log) (*mongo.UpdateResult, error) {
 	entry.UpdatedAt = time.Now()
 
 	update := bson.D{
-		{"$set", bson.D{
-			{"name", entry.Name},
-			{"data", entry.Data},
-			{"updated_at", entry.UpdatedAt},
+		{Key: "$set", Value: bson.D{
+			{Key: "name", Value: entry.Name},
+			{Key: "data", Value: entry.Data},
+			{Key: "updated_at", Value: entry.UpdatedAt},
 		}},
 	}
 
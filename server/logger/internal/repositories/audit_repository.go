@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"logger/types"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// auditRepository keeps the audit trail in its own collection, keyed by
+// sequence number. The unique _id is what serializes concurrent appends.
+type auditRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditRepository(db *mongo.Database) types.AuditRepositoryInterface {
+	return &auditRepository{
+		collection: db.Collection("audit"),
+	}
+}
+
+func (r *auditRepository) Last(ctx context.Context) (*types.AuditEntry, error) {
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	var entry types.AuditEntry
+	err := r.collection.FindOne(ctx, bson.M{}, opts).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry *types.AuditEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.ErrAuditConflict
+	}
+	return err
+}
+
+func (r *auditRepository) FindBySeq(ctx context.Context, seq int64) (*types.AuditEntry, error) {
+	var entry types.AuditEntry
+	err := r.collection.FindOne(ctx, bson.M{"_id": seq}).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+func (r *auditRepository) FindAfter(ctx context.Context, after int64, limit int) ([]types.AuditEntry, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$gt": after}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []types.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *auditRepository) Walk(ctx context.Context, fn func(types.AuditEntry) error) error {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetBatchSize(500)
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry types.AuditEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
@@ -0,0 +1,208 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logger/types"
+	"time"
+)
+
+// AuditFactory returns an empty audit repository and a function that
+// disposes of it.
+type AuditFactory func(ctx context.Context) (types.AuditRepositoryInterface, func(), error)
+
+type AuditCheck struct {
+	Name string
+	Run  func(ctx context.Context, repo types.AuditRepositoryInterface) error
+}
+
+// AuditChecks is the audit trail suite, in the order it runs.
+var AuditChecks = []AuditCheck{
+	{"audit append", checkAuditAppend},
+	{"audit paging", checkAuditPaging},
+	{"audit hash round trip", checkAuditRoundTrip},
+}
+
+// RunAudit runs every audit check against a fresh repository from factory.
+func RunAudit(ctx context.Context, factory AuditFactory) []Result {
+	results := make([]Result, 0, len(AuditChecks))
+
+	for _, check := range AuditChecks {
+		start := time.Now()
+		err := runAuditCheck(ctx, factory, check)
+		results = append(results, Result{
+			Name:     check.Name,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+
+	return results
+}
+
+func runAuditCheck(ctx context.Context, factory AuditFactory, check AuditCheck) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	repo, cleanup, err := factory(ctx)
+	if err != nil {
+		return fmt.Errorf("creating repository: %w", err)
+	}
+	defer cleanup()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return check.Run(ctx, repo)
+}
+
+// appendAudit chains n entries onto the trail.
+func appendAudit(ctx context.Context, repo types.AuditRepositoryInterface, n int) ([]types.AuditEntry, error) {
+	var entries []types.AuditEntry
+	prev := ""
+
+	for i := 1; i <= n; i++ {
+		entry := types.AuditEntry{
+			Seq:       int64(i),
+			Name:      "audit:login",
+			Data:      fmt.Sprintf("event %d", i),
+			Level:     "INFO",
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+			PrevHash:  prev,
+		}
+
+		var err error
+		if entry.Hash, err = entry.ComputeHash(); err != nil {
+			return nil, err
+		}
+		if err := repo.Append(ctx, &entry); err != nil {
+			return nil, fmt.Errorf("Append %d: %w", i, err)
+		}
+
+		entries = append(entries, entry)
+		prev = entry.Hash
+	}
+
+	return entries, nil
+}
+
+func checkAuditAppend(ctx context.Context, repo types.AuditRepositoryInterface) error {
+	head, err := repo.Last(ctx)
+	if err != nil || head != nil {
+		return fmt.Errorf("Last of an empty trail returned %v, %v; want nil, nil", head, err)
+	}
+
+	entries, err := appendAudit(ctx, repo, 2)
+	if err != nil {
+		return err
+	}
+
+	head, err = repo.Last(ctx)
+	if err != nil || head == nil {
+		return fmt.Errorf("Last: %v", err)
+	}
+	if head.Seq != 2 || head.Hash != entries[1].Hash {
+		return fmt.Errorf("Last returned entry %d", head.Seq)
+	}
+
+	taken := entries[1]
+	taken.Name = "audit:forged"
+	if err := repo.Append(ctx, &taken); !errors.Is(err, types.ErrAuditConflict) {
+		return fmt.Errorf("appending a taken seq returned %v, want ErrAuditConflict", err)
+	}
+
+	found, err := repo.FindBySeq(ctx, 1)
+	if err != nil || found == nil {
+		return fmt.Errorf("FindBySeq: %v", err)
+	}
+	if found.Hash != entries[0].Hash {
+		return errors.New("FindBySeq returned a different entry")
+	}
+
+	missing, err := repo.FindBySeq(ctx, 99)
+	if err != nil || missing != nil {
+		return fmt.Errorf("FindBySeq of a missing seq returned %v, %v; want nil, nil", missing, err)
+	}
+
+	return nil
+}
+
+func checkAuditPaging(ctx context.Context, repo types.AuditRepositoryInterface) error {
+	if _, err := appendAudit(ctx, repo, 5); err != nil {
+		return err
+	}
+
+	page, err := repo.FindAfter(ctx, 2, 2)
+	if err != nil {
+		return fmt.Errorf("FindAfter: %w", err)
+	}
+	if len(page) != 2 || page[0].Seq != 3 || page[1].Seq != 4 {
+		return fmt.Errorf("FindAfter(2, 2) returned %d entries", len(page))
+	}
+
+	page, err = repo.FindAfter(ctx, 5, 10)
+	if err != nil || page == nil || len(page) != 0 {
+		return fmt.Errorf("FindAfter past the head returned %v, %v; want an empty slice", page, err)
+	}
+
+	var walked []int64
+	err = repo.Walk(ctx, func(entry types.AuditEntry) error {
+		walked = append(walked, entry.Seq)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Walk: %w", err)
+	}
+	if fmt.Sprint(walked) != "[1 2 3 4 5]" {
+		return fmt.Errorf("Walk visited %v", walked)
+	}
+
+	return nil
+}
+
+// checkAuditRoundTrip stores structured content and checks its hash still
+// matches when read back, whatever the backend does to key order and
+// number types.
+func checkAuditRoundTrip(ctx context.Context, repo types.AuditRepositoryInterface) error {
+	entry := types.AuditEntry{
+		Seq:  1,
+		Name: "audit:role_change",
+		Data: map[string]any{
+			"zeta":  "last",
+			"alpha": map[string]any{"b": 2, "a": []any{1, "two", true}},
+			"count": 3,
+		},
+		Level:      "WARNING",
+		Service:    "auth-service",
+		Tags:       []string{"audit", "roles"},
+		Attributes: map[string]any{"attempt": 2, "admin": true},
+		CreatedAt:  base.Add(123 * time.Millisecond),
+	}
+
+	var err error
+	if entry.Hash, err = entry.ComputeHash(); err != nil {
+		return err
+	}
+	if err := repo.Append(ctx, &entry); err != nil {
+		return fmt.Errorf("Append: %w", err)
+	}
+
+	found, err := repo.FindBySeq(ctx, 1)
+	if err != nil || found == nil {
+		return fmt.Errorf("FindBySeq: %v", err)
+	}
+
+	hash, err := found.ComputeHash()
+	if err != nil {
+		return fmt.Errorf("hashing the stored entry: %w", err)
+	}
+	if hash != entry.Hash {
+		return errors.New("the stored entry no longer matches its hash")
+	}
+
+	return nil
+}
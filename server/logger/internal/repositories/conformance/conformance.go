@@ -0,0 +1,753 @@
+// Package conformance checks that a storage backend behaves the way the log
+// service expects. Every implementation of types.LogRepositoryInterface
+// must pass the same suite; cmd/conformance runs it against each backend.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logger/types"
+	"slices"
+	"time"
+)
+
+// Factory returns an empty repository and a function that disposes of it.
+// Each check gets its own repository.
+type Factory func(ctx context.Context) (types.LogRepositoryInterface, func(), error)
+
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, repo types.LogRepositoryInterface) error
+}
+
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Checks is the suite, in the order it runs.
+var Checks = []Check{
+	{"create and find", checkCreateAndFind},
+	{"update", checkUpdate},
+	{"delete", checkDelete},
+	{"trash", checkTrash},
+	{"create many", checkCreateLogs},
+	{"import", checkImport},
+	{"pagination", checkPagination},
+	{"filters", checkFilters},
+	{"search", checkSearch},
+	{"stats", checkStats},
+	{"histogram", checkHistogram},
+	{"stream and delete many", checkStreamAndDeleteMany},
+	{"tail", checkTail},
+	{"drop", checkDrop},
+}
+
+// Run runs every check against a fresh repository from factory.
+func Run(ctx context.Context, factory Factory) []Result {
+	results := make([]Result, 0, len(Checks))
+
+	for _, check := range Checks {
+		start := time.Now()
+		err := runCheck(ctx, factory, check)
+		results = append(results, Result{
+			Name:     check.Name,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+
+	return results
+}
+
+func runCheck(ctx context.Context, factory Factory, check Check) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	repo, cleanup, err := factory(ctx)
+	if err != nil {
+		return fmt.Errorf("creating repository: %w", err)
+	}
+	defer cleanup()
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("EnsureIndexes: %w", err)
+	}
+
+	return check.Run(ctx, repo)
+}
+
+// base is the creation time of seeded entries. It is on an hour boundary so
+// histogram buckets are predictable.
+var base = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// seed imports entries with fixed IDs and timestamps, one minute apart.
+func seed(ctx context.Context, repo types.LogRepositoryInterface, logs ...types.Log) ([]types.Log, error) {
+	for i := range logs {
+		if logs[i].ID == "" {
+			logs[i].ID = fmt.Sprintf("65e1a000000000000000%04x", i+1)
+		}
+		if logs[i].CreatedAt.IsZero() {
+			logs[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		}
+		logs[i].UpdatedAt = logs[i].CreatedAt
+		if logs[i].Data == nil {
+			logs[i].Data = fmt.Sprintf("entry %d", i+1)
+		}
+	}
+
+	inserted, skipped, err := repo.Import(ctx, logs)
+	if err != nil {
+		return nil, fmt.Errorf("Import: %w", err)
+	}
+	if inserted != int64(len(logs)) || skipped != 0 {
+		return nil, fmt.Errorf("Import: inserted %d and skipped %d of %d", inserted, skipped, len(logs))
+	}
+
+	return logs, nil
+}
+
+func ids(logs []types.Log) []string {
+	out := make([]string, len(logs))
+	for i, log := range logs {
+		out[i] = log.ID
+	}
+	return out
+}
+
+func expectIDs(what string, got []types.Log, want ...string) error {
+	if !slices.Equal(ids(got), want) {
+		return fmt.Errorf("%s: got ids %v, want %v", what, ids(got), want)
+	}
+	return nil
+}
+
+func checkCreateAndFind(ctx context.Context, repo types.LogRepositoryInterface) error {
+	log := &types.Log{
+		Name:       "create",
+		Data:       map[string]any{"user": "ada"},
+		Level:      "WARNING",
+		Service:    "broker-service",
+		Tags:       []string{"a", "b"},
+		Attributes: map[string]any{"attempt": float64(2)},
+	}
+	if err := repo.Create(ctx, log); err != nil {
+		return fmt.Errorf("Create: %w", err)
+	}
+	if log.ID == "" || log.CreatedAt.IsZero() || log.UpdatedAt.IsZero() {
+		return errors.New("Create did not set the id and timestamps")
+	}
+
+	found, err := repo.FindByID(ctx, log.ID)
+	if err != nil {
+		return fmt.Errorf("FindByID: %w", err)
+	}
+	if found == nil {
+		return errors.New("FindByID did not find the created entry")
+	}
+	if found.ID != log.ID || found.Name != "create" || found.Level != "WARNING" || found.Service != "broker-service" {
+		return fmt.Errorf("FindByID returned %+v", found)
+	}
+	if !slices.Equal(found.Tags, []string{"a", "b"}) {
+		return fmt.Errorf("tags were stored as %v", found.Tags)
+	}
+	if attempt, ok := found.Attributes["attempt"].(float64); !ok || attempt != 2 {
+		return fmt.Errorf("attributes were stored as %v", found.Attributes)
+	}
+
+	missing, err := repo.FindByID(ctx, "65e1a0000000000000ffffff")
+	if err != nil || missing != nil {
+		return fmt.Errorf("FindByID of a missing id returned %v, %v; want nil, nil", missing, err)
+	}
+
+	if _, err := repo.FindByID(ctx, "not-an-id"); !errors.Is(err, types.ErrInvalidLogID) {
+		return fmt.Errorf("FindByID of an invalid id returned %v, want ErrInvalidLogID", err)
+	}
+	if err := repo.Delete(ctx, "not-an-id"); !errors.Is(err, types.ErrInvalidLogID) {
+		return fmt.Errorf("Delete of an invalid id returned %v, want ErrInvalidLogID", err)
+	}
+
+	return nil
+}
+
+func checkUpdate(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo, types.Log{Name: "before", Level: "INFO", Service: "svc"})
+	if err != nil {
+		return err
+	}
+
+	update := logs[0]
+	update.Name = "after"
+	update.Data = "changed"
+	update.Level = "ERROR"
+	update.Tags = []string{"edited"}
+	update.Version = 2
+	if err := repo.Update(ctx, update.ID, &update); err != nil {
+		return fmt.Errorf("Update: %w", err)
+	}
+
+	found, err := repo.FindByID(ctx, update.ID)
+	if err != nil || found == nil {
+		return fmt.Errorf("FindByID after Update: %v", err)
+	}
+	if found.Name != "after" || found.Data != "changed" || found.Level != "ERROR" || !slices.Equal(found.Tags, []string{"edited"}) {
+		return fmt.Errorf("Update stored %+v", found)
+	}
+	if found.Service != "svc" {
+		return errors.New("Update cleared the service")
+	}
+	if !found.CreatedAt.Equal(logs[0].CreatedAt) {
+		return errors.New("Update changed created_at")
+	}
+	if !found.UpdatedAt.After(found.CreatedAt) {
+		return errors.New("Update did not advance updated_at")
+	}
+	if found.Version != 2 {
+		return fmt.Errorf("Update stored version %d, want 2", found.Version)
+	}
+
+	// A second writer that also read version 1 must lose.
+	stale := update
+	stale.Name = "stale"
+	if err := repo.Update(ctx, update.ID, &stale); !errors.Is(err, types.ErrLogConflict) {
+		return fmt.Errorf("Update from a stale version returned %v, want ErrLogConflict", err)
+	}
+
+	if err := repo.Update(ctx, "65e1a0000000000000ffffff", &update); !errors.Is(err, types.ErrLogNotFound) {
+		return fmt.Errorf("Update of a missing id returned %v, want ErrLogNotFound", err)
+	}
+
+	return nil
+}
+
+func checkDelete(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo, types.Log{Name: "doomed"}, types.Log{Name: "kept"})
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Delete(ctx, logs[0].ID); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+
+	// Deleting moves the entry to the trash: it is still found by ID but
+	// no longer listed.
+	found, err := repo.FindByID(ctx, logs[0].ID)
+	if err != nil || found == nil {
+		return fmt.Errorf("trashed entry not found by ID: %v", err)
+	}
+	if found.DeletedAt == nil {
+		return errors.New("Delete did not set deleted_at")
+	}
+	live, err := repo.FindAll(ctx, types.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("FindAll: %w", err)
+	}
+	if err := expectIDs("live entries", live, logs[1].ID); err != nil {
+		return err
+	}
+
+	if err := repo.Delete(ctx, logs[0].ID); !errors.Is(err, types.ErrLogNotFound) {
+		return fmt.Errorf("deleting a trashed entry returned %v, want ErrLogNotFound", err)
+	}
+	if err := repo.Update(ctx, logs[0].ID, found); !errors.Is(err, types.ErrLogNotFound) {
+		return fmt.Errorf("updating a trashed entry returned %v, want ErrLogNotFound", err)
+	}
+	if err := repo.Delete(ctx, "65e1a0000000000000ffffff"); !errors.Is(err, types.ErrLogNotFound) {
+		return fmt.Errorf("deleting a missing entry returned %v, want ErrLogNotFound", err)
+	}
+
+	return nil
+}
+
+func checkTrash(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo,
+		types.Log{Name: "a"}, types.Log{Name: "b"}, types.Log{Name: "c"},
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Delete(ctx, logs[0].ID); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+
+	trashed, err := repo.Trash(ctx, types.LogFilter{}, "drop-1")
+	if err != nil {
+		return fmt.Errorf("Trash: %w", err)
+	}
+	if trashed != 2 {
+		return fmt.Errorf("Trash moved %d entries, want the 2 live ones", trashed)
+	}
+
+	trash, err := repo.FindAll(ctx, types.LogFilter{Trash: true})
+	if err != nil {
+		return fmt.Errorf("FindAll of the trash: %w", err)
+	}
+	if err := expectIDs("trash", trash, logs[2].ID, logs[1].ID, logs[0].ID); err != nil {
+		return err
+	}
+
+	restored, err := repo.RestoreMany(ctx, types.LogFilter{Snapshot: "drop-1"})
+	if err != nil {
+		return fmt.Errorf("RestoreMany: %w", err)
+	}
+	if restored != 2 {
+		return fmt.Errorf("RestoreMany restored %d entries, want 2", restored)
+	}
+
+	if err := repo.Restore(ctx, logs[0].ID); err != nil {
+		return fmt.Errorf("Restore: %w", err)
+	}
+	if err := repo.Restore(ctx, logs[0].ID); !errors.Is(err, types.ErrLogNotFound) {
+		return fmt.Errorf("restoring a live entry returned %v, want ErrLogNotFound", err)
+	}
+
+	found, err := repo.FindByID(ctx, logs[1].ID)
+	if err != nil || found == nil {
+		return fmt.Errorf("FindByID: %v", err)
+	}
+	if found.DeletedAt != nil || found.Snapshot != "" {
+		return errors.New("restoring left deleted_at or snapshot set")
+	}
+
+	live, err := repo.FindAll(ctx, types.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("FindAll: %w", err)
+	}
+	if err := expectIDs("after restore", live, logs[2].ID, logs[1].ID, logs[0].ID); err != nil {
+		return err
+	}
+
+	// Purging only reaches entries deleted before the cutoff.
+	if err := repo.Delete(ctx, logs[0].ID); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	purged, err := repo.DeleteMany(ctx, types.LogFilter{Trash: true, DeletedBefore: time.Now().Add(-time.Hour)})
+	if err != nil || purged != 0 {
+		return fmt.Errorf("purging before an hour ago deleted %d: %v", purged, err)
+	}
+	purged, err = repo.DeleteMany(ctx, types.LogFilter{Trash: true, DeletedBefore: time.Now().Add(time.Minute)})
+	if err != nil || purged != 1 {
+		return fmt.Errorf("purging the trash deleted %d: %v", purged, err)
+	}
+	if found, _ := repo.FindByID(ctx, logs[0].ID); found != nil {
+		return errors.New("purged entry still found")
+	}
+
+	return nil
+}
+
+func checkCreateLogs(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs := []*types.Log{
+		{Name: "bulk", Data: "one", Level: "INFO"},
+		{Name: "bulk", Data: "two", Level: "ERROR"},
+	}
+
+	errs, err := repo.CreateLogs(ctx, logs)
+	if err != nil {
+		return fmt.Errorf("CreateLogs: %w", err)
+	}
+	if len(errs) != len(logs) {
+		return fmt.Errorf("CreateLogs returned %d results for %d entries", len(errs), len(logs))
+	}
+
+	for i, log := range logs {
+		if errs[i] != nil {
+			return fmt.Errorf("CreateLogs entry %d: %w", i, errs[i])
+		}
+		if log.ID == "" {
+			return fmt.Errorf("CreateLogs did not set the id of entry %d", i)
+		}
+		if found, err := repo.FindByID(ctx, log.ID); err != nil || found == nil {
+			return fmt.Errorf("entry %d not found after CreateLogs: %v", i, err)
+		}
+	}
+
+	return nil
+}
+
+func checkImport(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo, types.Log{Name: "restored"})
+	if err != nil {
+		return err
+	}
+
+	found, err := repo.FindByID(ctx, logs[0].ID)
+	if err != nil || found == nil {
+		return fmt.Errorf("imported entry not found by its id: %v", err)
+	}
+	if !found.CreatedAt.Equal(base) {
+		return fmt.Errorf("import changed created_at to %s", found.CreatedAt)
+	}
+
+	again := append(logs, types.Log{ID: "65e1a0000000000000000fff", Name: "new", Data: "x", CreatedAt: base, UpdatedAt: base})
+	inserted, skipped, err := repo.Import(ctx, again)
+	if err != nil {
+		return fmt.Errorf("second Import: %w", err)
+	}
+	if inserted != 1 || skipped != 1 {
+		return fmt.Errorf("second Import inserted %d and skipped %d, want 1 and 1", inserted, skipped)
+	}
+
+	return nil
+}
+
+func checkPagination(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo,
+		types.Log{Name: "page"}, types.Log{Name: "page"}, types.Log{Name: "page"},
+		types.Log{Name: "page"}, types.Log{Name: "page"},
+	)
+	if err != nil {
+		return err
+	}
+	all := ids(logs)
+
+	for _, sort := range []types.SortDirection{types.SortDesc, types.SortAsc} {
+		want := slices.Clone(all)
+		if sort == types.SortDesc {
+			slices.Reverse(want)
+		}
+
+		var got []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				return fmt.Errorf("%s: pagination did not end", sort)
+			}
+
+			page, err := repo.FindPage(ctx, types.LogQuery{Cursor: cursor, Limit: 2, Sort: sort})
+			if err != nil {
+				return fmt.Errorf("%s: FindPage: %w", sort, err)
+			}
+			got = append(got, ids(page.Logs)...)
+
+			if !page.HasMore {
+				break
+			}
+			if page.NextCursor == "" {
+				return fmt.Errorf("%s: HasMore without a cursor", sort)
+			}
+			cursor = page.NextCursor
+		}
+
+		if !slices.Equal(got, want) {
+			return fmt.Errorf("%s: paged through %v, want %v", sort, got, want)
+		}
+	}
+
+	if _, err := repo.FindPage(ctx, types.LogQuery{Cursor: "%%%", Limit: 2}); !errors.Is(err, types.ErrInvalidCursor) {
+		return fmt.Errorf("an invalid cursor returned %v, want ErrInvalidCursor", err)
+	}
+
+	all2, err := repo.FindAll(ctx, types.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("FindAll: %w", err)
+	}
+	want := slices.Clone(all)
+	slices.Reverse(want)
+	return expectIDs("FindAll", all2, want...)
+}
+
+func checkFilters(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo,
+		types.Log{Name: "login", Level: "INFO", Service: "auth", Tags: []string{"user"}, Data: "ada signed in"},
+		types.Log{Name: "login", Level: "ERROR", Service: "auth", Tags: []string{"user", "failure"}, Data: "bad password"},
+		types.Log{Name: "mail", Level: "WARNING", Service: "mailer", TraceID: "trace-1", Data: "queue slow"},
+		// Entries from before levels existed have none and count as INFO.
+		types.Log{Name: "legacy", Data: "old entry"},
+		types.Log{Name: "webhook", Level: "ERROR", Service: "hooks", Data: map[string]any{"error": "Signature mismatch", "status": float64(401)}},
+	)
+	if err != nil {
+		return err
+	}
+	id := ids(logs)
+
+	cases := []struct {
+		what   string
+		filter types.LogFilter
+		want   []string
+	}{
+		{"level INFO", types.LogFilter{Level: "INFO"}, []string{id[3], id[0]}},
+		{"level ERROR", types.LogFilter{Level: "ERROR"}, []string{id[4], id[1]}},
+		{"names", types.LogFilter{Names: []string{"mail", "legacy"}}, []string{id[3], id[2]}},
+		{"exclude names", types.LogFilter{ExcludeNames: []string{"login"}}, []string{id[4], id[3], id[2]}},
+		{"exclude INFO", types.LogFilter{ExcludeLevels: []string{"INFO"}}, []string{id[4], id[2], id[1]}},
+		{"from", types.LogFilter{From: logs[2].CreatedAt}, []string{id[4], id[3], id[2]}},
+		{"to is exclusive", types.LogFilter{To: logs[1].CreatedAt}, []string{id[0]}},
+		{"service", types.LogFilter{Service: "auth"}, []string{id[1], id[0]}},
+		{"trace id", types.LogFilter{TraceID: "trace-1"}, []string{id[2]}},
+		{"all tags", types.LogFilter{Tags: []string{"user", "failure"}}, []string{id[1]}},
+		{"data substring", types.LogFilter{Search: "PASSWORD"}, []string{id[1]}},
+		{"object data substring", types.LogFilter{Search: "signature"}, []string{id[4]}},
+		{"ids", types.LogFilter{IDs: []string{id[0], id[2], "not-an-id"}}, []string{id[2], id[0]}},
+		{"no ids", types.LogFilter{IDs: []string{}}, nil},
+	}
+
+	for _, c := range cases {
+		got, err := repo.FindAll(ctx, c.filter)
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.what, err)
+		}
+		if err := expectIDs(c.what, got, c.want...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkSearch(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo,
+		types.Log{Name: "payment", Data: "card declined"},
+		types.Log{Name: "checkout", Data: map[string]any{"step": "payment", "status": "ok"}},
+		types.Log{Name: "login", Data: "nothing relevant"},
+	)
+	if err != nil {
+		return err
+	}
+
+	result, err := repo.Search(ctx, types.SearchQuery{Text: "payment", Limit: 10})
+	if err != nil {
+		return fmt.Errorf("Search: %w", err)
+	}
+	if result.Total != 2 || len(result.Hits) != 2 {
+		return fmt.Errorf("Search found %d hits (total %d), want 2", len(result.Hits), result.Total)
+	}
+	// A match in the name outweighs one in nested data.
+	if result.Hits[0].Log.ID != logs[0].ID || result.Hits[1].Log.ID != logs[1].ID {
+		return fmt.Errorf("Search ranked %v, want %v", []string{result.Hits[0].Log.ID, result.Hits[1].Log.ID}, ids(logs[:2]))
+	}
+	if result.Hits[0].Score <= result.Hits[1].Score {
+		return errors.New("Search scores are not descending")
+	}
+
+	result, err = repo.Search(ctx, types.SearchQuery{Text: "payment -declined", Limit: 10})
+	if err != nil {
+		return fmt.Errorf("Search with exclusion: %w", err)
+	}
+	if len(result.Hits) != 1 || result.Hits[0].Log.ID != logs[1].ID {
+		return fmt.Errorf("Search with exclusion returned %d hits", len(result.Hits))
+	}
+
+	result, err = repo.Search(ctx, types.SearchQuery{Text: "payment", Limit: 1, Offset: 1})
+	if err != nil {
+		return fmt.Errorf("Search with offset: %w", err)
+	}
+	if result.Total != 2 || len(result.Hits) != 1 || result.Hits[0].Log.ID != logs[1].ID {
+		return errors.New("Search offset did not return the second hit")
+	}
+
+	return nil
+}
+
+func checkStats(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo,
+		types.Log{Name: "a", Level: "INFO"},
+		types.Log{Name: "b", Level: "ERROR"},
+		types.Log{Name: "b", Level: "ERROR"},
+		types.Log{Name: "c"},
+	)
+	if err != nil {
+		return err
+	}
+
+	stats, err := repo.GetStats(ctx, types.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("GetStats: %w", err)
+	}
+	if stats.TotalLogs != 4 {
+		return fmt.Errorf("total is %d, want 4", stats.TotalLogs)
+	}
+	if !stats.FirstLogTime.Equal(logs[0].CreatedAt) || !stats.LastLogTime.Equal(logs[3].CreatedAt) {
+		return fmt.Errorf("first and last are %s and %s", stats.FirstLogTime, stats.LastLogTime)
+	}
+	if stats.ByLevel["INFO"] != 2 || stats.ByLevel["ERROR"] != 2 {
+		return fmt.Errorf("by level is %v", stats.ByLevel)
+	}
+	if len(stats.ByName) != 3 || stats.ByName[0] != (types.NameCount{Name: "b", Count: 2}) {
+		return fmt.Errorf("by name is %v", stats.ByName)
+	}
+
+	empty, err := repo.GetStats(ctx, types.LogFilter{Names: []string{"none"}})
+	if err != nil {
+		return fmt.Errorf("GetStats with no matches: %w", err)
+	}
+	if empty.TotalLogs != 0 || empty.ByLevel == nil || empty.ByName == nil {
+		return fmt.Errorf("stats with no matches are %+v", empty)
+	}
+
+	return nil
+}
+
+func checkHistogram(ctx context.Context, repo types.LogRepositoryInterface) error {
+	_, err := seed(ctx, repo,
+		types.Log{Name: "h", CreatedAt: base.Add(5 * time.Minute)},
+		types.Log{Name: "h", CreatedAt: base.Add(50 * time.Minute)},
+		types.Log{Name: "h", CreatedAt: base.Add(2*time.Hour + time.Minute)},
+	)
+	if err != nil {
+		return err
+	}
+
+	query := types.HistogramQuery{Bucket: types.BucketHour}
+	query.From = base
+	query.To = base.Add(4 * time.Hour)
+
+	buckets, err := repo.Histogram(ctx, query)
+	if err != nil {
+		return fmt.Errorf("Histogram: %w", err)
+	}
+
+	want := []int64{2, 0, 1, 0}
+	if len(buckets) != len(want) {
+		return fmt.Errorf("got %d buckets, want %d", len(buckets), len(want))
+	}
+	for i, bucket := range buckets {
+		if !bucket.Start.Equal(base.Add(time.Duration(i) * time.Hour)) {
+			return fmt.Errorf("bucket %d starts at %s", i, bucket.Start)
+		}
+		if bucket.Count != want[i] {
+			return fmt.Errorf("bucket %d counts %d, want %d", i, bucket.Count, want[i])
+		}
+	}
+
+	return nil
+}
+
+func checkStreamAndDeleteMany(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo,
+		types.Log{Name: "old", Level: "INFO"},
+		types.Log{Name: "old", Level: "ERROR"},
+		types.Log{Name: "new", Level: "INFO"},
+	)
+	if err != nil {
+		return err
+	}
+
+	var streamed []types.Log
+	err = repo.Stream(ctx, types.LogFilter{}, func(log types.Log) error {
+		streamed = append(streamed, log)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Stream: %w", err)
+	}
+	if err := expectIDs("Stream", streamed, ids(logs)...); err != nil {
+		return err
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.Stream(ctx, types.LogFilter{}, func(types.Log) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		return errors.New("Stream did not stop at the callback's error")
+	}
+
+	deleted, err := repo.DeleteMany(ctx, types.LogFilter{Names: []string{"old"}, To: logs[2].CreatedAt})
+	if err != nil {
+		return fmt.Errorf("DeleteMany: %w", err)
+	}
+	if deleted != 2 {
+		return fmt.Errorf("DeleteMany deleted %d, want 2", deleted)
+	}
+
+	left, err := repo.FindAll(ctx, types.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("FindAll: %w", err)
+	}
+	return expectIDs("after DeleteMany", left, logs[2].ID)
+}
+
+func checkTail(ctx context.Context, repo types.LogRepositoryInterface) error {
+	logs, err := seed(ctx, repo, types.Log{Name: "tail"}, types.Log{Name: "tail"})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	received := make(chan types.Log, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.Tail(ctx, types.TailQuery{
+			LogFilter: types.LogFilter{Names: []string{"tail"}},
+			AfterID:   logs[0].ID,
+		}, func(log types.Log) error {
+			received <- log
+			return nil
+		})
+	}()
+
+	// Resuming replays what came after the given entry, then follows new
+	// entries. Entries outside the filter are not delivered.
+	if err := repo.Create(ctx, &types.Log{Name: "other", Data: "skip", Level: "INFO"}); err != nil {
+		return fmt.Errorf("Create: %w", err)
+	}
+	created := &types.Log{Name: "tail", Data: "live", Level: "INFO"}
+	if err := repo.Create(ctx, created); err != nil {
+		return fmt.Errorf("Create: %w", err)
+	}
+
+	want := []string{logs[1].ID, created.ID}
+	var got []string
+	for len(got) < len(want) {
+		select {
+		case log := <-received:
+			got = append(got, log.ID)
+		case err := <-done:
+			return fmt.Errorf("Tail stopped early: %v", err)
+		case <-ctx.Done():
+			return fmt.Errorf("Tail delivered %v, want %v", got, want)
+		}
+	}
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("Tail delivered %v, want %v", got, want)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		return errors.New("Tail did not stop when its context ended")
+	}
+
+	err = repo.Tail(context.Background(), types.TailQuery{AfterID: "65e1a0000000000000ffffff"}, func(types.Log) error { return nil })
+	if !errors.Is(err, types.ErrInvalidCursor) {
+		return fmt.Errorf("tailing after a missing id returned %v, want ErrInvalidCursor", err)
+	}
+
+	return nil
+}
+
+func checkDrop(ctx context.Context, repo types.LogRepositoryInterface) error {
+	if _, err := seed(ctx, repo, types.Log{Name: "x"}, types.Log{Name: "y"}); err != nil {
+		return err
+	}
+
+	if err := repo.DropCollection(ctx); err != nil {
+		return fmt.Errorf("DropCollection: %w", err)
+	}
+
+	left, err := repo.FindAll(ctx, types.LogFilter{})
+	if err != nil {
+		return fmt.Errorf("FindAll: %w", err)
+	}
+	if len(left) != 0 {
+		return fmt.Errorf("%d entries left after DropCollection", len(left))
+	}
+
+	return nil
+}
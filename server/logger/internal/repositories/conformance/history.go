@@ -0,0 +1,169 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logger/types"
+	"time"
+)
+
+// HistoryFactory returns an empty history repository and a function that
+// disposes of it.
+type HistoryFactory func(ctx context.Context) (types.HistoryRepositoryInterface, func(), error)
+
+type HistoryCheck struct {
+	Name string
+	Run  func(ctx context.Context, repo types.HistoryRepositoryInterface) error
+}
+
+// HistoryChecks is the log history suite, in the order it runs.
+var HistoryChecks = []HistoryCheck{
+	{"history versions", checkHistoryVersions},
+	{"history delete", checkHistoryDelete},
+}
+
+// RunHistory runs every history check against a fresh repository from
+// factory.
+func RunHistory(ctx context.Context, factory HistoryFactory) []Result {
+	results := make([]Result, 0, len(HistoryChecks))
+
+	for _, check := range HistoryChecks {
+		start := time.Now()
+		err := runHistoryCheck(ctx, factory, check)
+		results = append(results, Result{
+			Name:     check.Name,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+
+	return results
+}
+
+func runHistoryCheck(ctx context.Context, factory HistoryFactory, check HistoryCheck) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	repo, cleanup, err := factory(ctx)
+	if err != nil {
+		return fmt.Errorf("creating repository: %w", err)
+	}
+	defer cleanup()
+
+	if err := repo.EnsureIndexes(ctx); err != nil {
+		return fmt.Errorf("EnsureIndexes: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return check.Run(ctx, repo)
+}
+
+func checkHistoryVersions(ctx context.Context, repo types.HistoryRepositoryInterface) error {
+	const id = "65e1a0000000000000000001"
+
+	// Added out of order, as racing updates may record them.
+	for _, n := range []int{2, 1, 3} {
+		version := types.LogVersion{
+			LogID:     id,
+			Version:   n,
+			Name:      fmt.Sprintf("v%d", n),
+			Data:      "data",
+			Level:     "INFO",
+			Tags:      []string{"t"},
+			UpdatedAt: base.Add(time.Duration(n) * time.Minute),
+			ChangedAt: base.Add(time.Duration(n+1) * time.Minute),
+			ChangedBy: "checker",
+			Diff:      []types.PatchOp{{Op: "replace", Path: "/name", Value: fmt.Sprintf("v%d", n+1), Old: fmt.Sprintf("v%d", n)}},
+		}
+		if err := repo.Add(ctx, &version); err != nil {
+			return fmt.Errorf("Add %d: %w", n, err)
+		}
+	}
+
+	other := types.LogVersion{LogID: "65e1a0000000000000000002", Version: 1, Name: "other", Level: "INFO"}
+	if err := repo.Add(ctx, &other); err != nil {
+		return fmt.Errorf("Add for another entry: %w", err)
+	}
+
+	taken := types.LogVersion{LogID: id, Version: 2, Name: "forged", Level: "INFO"}
+	if err := repo.Add(ctx, &taken); !errors.Is(err, types.ErrLogConflict) {
+		return fmt.Errorf("adding a recorded version returned %v, want ErrLogConflict", err)
+	}
+
+	versions, err := repo.FindVersions(ctx, id)
+	if err != nil {
+		return fmt.Errorf("FindVersions: %w", err)
+	}
+	if len(versions) != 3 {
+		return fmt.Errorf("FindVersions returned %d versions, want 3", len(versions))
+	}
+	for i, version := range versions {
+		if version.Version != i+1 || version.Name != fmt.Sprintf("v%d", i+1) {
+			return fmt.Errorf("FindVersions returned version %d (%s) at position %d", version.Version, version.Name, i)
+		}
+	}
+
+	found, err := repo.FindVersion(ctx, id, 2)
+	if err != nil || found == nil {
+		return fmt.Errorf("FindVersion: %v", err)
+	}
+	if found.ChangedBy != "checker" || !found.ChangedAt.Equal(base.Add(3*time.Minute)) || len(found.Diff) != 1 || found.Diff[0].Path != "/name" {
+		return fmt.Errorf("FindVersion returned %+v", found)
+	}
+
+	missing, err := repo.FindVersion(ctx, id, 9)
+	if err != nil || missing != nil {
+		return fmt.Errorf("FindVersion of a missing version returned %v, %v; want nil, nil", missing, err)
+	}
+
+	empty, err := repo.FindVersions(ctx, "65e1a0000000000000ffffff")
+	if err != nil || len(empty) != 0 {
+		return fmt.Errorf("FindVersions of an entry without history returned %d versions, %v", len(empty), err)
+	}
+
+	return nil
+}
+
+func checkHistoryDelete(ctx context.Context, repo types.HistoryRepositoryInterface) error {
+	ids := []string{"65e1a0000000000000000001", "65e1a0000000000000000002", "65e1a0000000000000000003"}
+	for _, id := range ids {
+		for n := 1; n <= 2; n++ {
+			version := types.LogVersion{LogID: id, Version: n, Name: fmt.Sprintf("v%d", n), Level: "INFO"}
+			if err := repo.Add(ctx, &version); err != nil {
+				return fmt.Errorf("Add %s/%d: %w", id, n, err)
+			}
+		}
+	}
+
+	if err := repo.DeleteFor(ctx, nil); err != nil {
+		return fmt.Errorf("DeleteFor with no ids: %w", err)
+	}
+	if err := repo.DeleteFor(ctx, []string{ids[0], ids[2], "65e1a0000000000000ffffff"}); err != nil {
+		return fmt.Errorf("DeleteFor: %w", err)
+	}
+
+	for _, id := range []string{ids[0], ids[2]} {
+		versions, err := repo.FindVersions(ctx, id)
+		if err != nil || len(versions) != 0 {
+			return fmt.Errorf("FindVersions of a deleted entry returned %d versions, %v", len(versions), err)
+		}
+	}
+	versions, err := repo.FindVersions(ctx, ids[1])
+	if err != nil || len(versions) != 2 {
+		return fmt.Errorf("FindVersions of a kept entry returned %d versions, %v; want 2", len(versions), err)
+	}
+
+	// Deleted versions no longer take up their numbers.
+	again := types.LogVersion{LogID: ids[0], Version: 1, Name: "again", Level: "INFO"}
+	if err := repo.Add(ctx, &again); err != nil {
+		return fmt.Errorf("Add after DeleteFor: %w", err)
+	}
+
+	return nil
+}
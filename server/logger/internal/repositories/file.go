@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"logger/types"
+	"os"
+)
+
+// compactBatch is how many entries one record holds when the journal is
+// rewritten.
+const compactBatch = 500
+
+// fileRepository is the memory repository made durable by an append-only
+// journal: every change is written to the file as one JSON line, and
+// synced, before it is applied. Opening the file replays it.
+type fileRepository struct {
+	*memoryRepository
+	journal *appendFile
+	// records counts the lines in the journal, which compaction brings
+	// down to what the live entries need.
+	records int
+}
+
+// NewFileRepository opens or creates the journal at path. The caller
+// should Close the repository on shutdown.
+func NewFileRepository(path string) (types.LogRepositoryInterface, error) {
+	r := &fileRepository{
+		memoryRepository: newMemoryRepository(),
+	}
+
+	journal, err := openAppendFile(path, func(record []byte) error {
+		var c change
+		if err := json.Unmarshal(record, &c); err != nil {
+			return err
+		}
+		r.apply(c)
+		r.records++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.journal = journal
+	r.memoryRepository.journal = r.append
+
+	return r, nil
+}
+
+// append is the memory repository's journal. It runs under the write lock.
+func (r *fileRepository) append(c change) error {
+	if err := r.journal.append(c); err != nil {
+		return err
+	}
+
+	r.records++
+	return nil
+}
+
+// EnsureIndexes compacts the journal, dropping records for entries that
+// have since been updated or deleted.
+func (r *fileRepository) EnsureIndexes(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	needed := (len(r.entries) + compactBatch - 1) / compactBatch
+	if r.records <= needed {
+		return nil
+	}
+
+	temp := r.journal.path + ".compact"
+	file, err := os.Create(temp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp)
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	records := 0
+
+	for start := 0; start < len(r.entries); start += compactBatch {
+		end := min(start+compactBatch, len(r.entries))
+		puts := make([]types.Log, 0, end-start)
+		for _, entry := range r.entries[start:end] {
+			puts = append(puts, entry.log)
+		}
+		if err := encoder.Encode(change{Puts: puts}); err != nil {
+			file.Close()
+			return err
+		}
+		records++
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp, r.journal.path); err != nil {
+		return err
+	}
+
+	// The old descriptor still points at the replaced file.
+	if err := r.journal.reopen(); err != nil {
+		return err
+	}
+	r.records = records
+
+	return nil
+}
+
+func (r *fileRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.journal.close()
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"encoding/json"
+	"logger/types"
+)
+
+// fileAuditRepository is the memory audit trail backed by an append-only
+// file holding one JSON entry per line. Entries are never rewritten, so
+// unlike the log journal it is not compacted.
+type fileAuditRepository struct {
+	*memoryAuditRepository
+	file *appendFile
+}
+
+// NewFileAuditRepository opens or creates the audit file at path. The
+// caller should Close the repository on shutdown.
+func NewFileAuditRepository(path string) (types.AuditRepositoryInterface, error) {
+	r := &fileAuditRepository{
+		memoryAuditRepository: &memoryAuditRepository{},
+	}
+
+	file, err := openAppendFile(path, func(record []byte) error {
+		var entry types.AuditEntry
+		if err := json.Unmarshal(record, &entry); err != nil {
+			return err
+		}
+		r.add(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.file = file
+	r.memoryAuditRepository.journal = func(entry types.AuditEntry) error {
+		return r.file.append(entry)
+	}
+
+	return r, nil
+}
+
+func (r *fileAuditRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.close()
+}
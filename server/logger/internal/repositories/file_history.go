@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"logger/types"
+	"maps"
+)
+
+// fileHistoryRepository is the memory history backed by an append-only
+// file holding one JSON version per line.
+type fileHistoryRepository struct {
+	*memoryHistoryRepository
+	file *appendFile
+}
+
+// NewFileHistoryRepository opens or creates the history file at path. The
+// caller should Close the repository on shutdown.
+func NewFileHistoryRepository(path string) (types.HistoryRepositoryInterface, error) {
+	r := &fileHistoryRepository{
+		memoryHistoryRepository: newMemoryHistoryRepository(),
+	}
+
+	file, err := openAppendFile(path, func(record []byte) error {
+		var version types.LogVersion
+		if err := json.Unmarshal(record, &version); err != nil {
+			return err
+		}
+		r.add(version)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.file = file
+	r.memoryHistoryRepository.journal = func(version types.LogVersion) error {
+		return r.file.append(version)
+	}
+
+	return r, nil
+}
+
+// DeleteFor rewrites the file without the entries' versions, so their
+// content leaves the disk rather than only the index.
+func (r *fileHistoryRepository) DeleteFor(ctx context.Context, logIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining := maps.Clone(r.versions)
+	for _, id := range logIDs {
+		delete(remaining, id)
+	}
+	if len(remaining) == len(r.versions) {
+		return nil
+	}
+
+	err := r.file.rewrite(func(write func(record any) error) error {
+		for _, versions := range remaining {
+			for _, version := range versions {
+				if err := write(version); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.versions = remaining
+	return nil
+}
+
+func (r *fileHistoryRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.close()
+}
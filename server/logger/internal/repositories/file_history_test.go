@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"logger/types"
+)
+
+func TestFileHistoryDeleteForSurvivesReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.ndjson")
+
+	repo, err := NewFileHistoryRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"purged", "kept"} {
+		if err := repo.Add(ctx, &types.LogVersion{LogID: id, Version: 1, Name: "v1", Level: "INFO"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.DeleteFor(ctx, []string{"purged"}); err != nil {
+		t.Fatal(err)
+	}
+	// Appends after the rewrite land in the new file.
+	if err := repo.Add(ctx, &types.LogVersion{LogID: "kept", Version: 2, Name: "v2", Level: "INFO"}); err != nil {
+		t.Fatal(err)
+	}
+	repo.(io.Closer).Close()
+
+	reopened, err := NewFileHistoryRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.(io.Closer).Close()
+
+	if versions, _ := reopened.FindVersions(ctx, "purged"); len(versions) != 0 {
+		t.Errorf("purged entry has %d versions after reopening", len(versions))
+	}
+	if versions, _ := reopened.FindVersions(ctx, "kept"); len(versions) != 2 {
+		t.Errorf("kept entry has %d versions after reopening, want 2", len(versions))
+	}
+}
@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"logger/types"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func buildFilter(filter types.LogFilter) bson.M {
+	query := bson.M{}
+
+	level := bson.M{}
+	if filter.Level != "" {
+		level["$in"] = levelValues(filter.Level)
+	}
+	if len(filter.ExcludeLevels) > 0 {
+		level["$nin"] = levelValues(filter.ExcludeLevels...)
+	}
+	if len(level) > 0 {
+		query["level"] = level
+	}
+
+	name := bson.M{}
+	if len(filter.Names) > 0 {
+		name["$in"] = filter.Names
+	}
+	if len(filter.ExcludeNames) > 0 {
+		name["$nin"] = filter.ExcludeNames
+	}
+	if len(name) > 0 {
+		query["name"] = name
+	}
+
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	if filter.Service != "" {
+		query["service"] = filter.Service
+	}
+	if filter.RequestID != "" {
+		query["request_id"] = filter.RequestID
+	}
+	if filter.TraceID != "" {
+		query["trace_id"] = filter.TraceID
+	}
+	if len(filter.Tags) > 0 {
+		query["tags"] = bson.M{"$all": filter.Tags}
+	}
+
+	if filter.Search != "" {
+		// data_text is the flattened text of data, so entries whose data is
+		// an object match too.
+		query["data_text"] = bson.M{"$regex": regexp.QuoteMeta(filter.Search), "$options": "i"}
+	}
+
+	// Null matches a missing field, so live entries need no deleted_at.
+	switch {
+	case !filter.Trash:
+		query["deleted_at"] = nil
+	case !filter.DeletedBefore.IsZero():
+		query["deleted_at"] = bson.M{"$ne": nil, "$lt": filter.DeletedBefore}
+	default:
+		query["deleted_at"] = bson.M{"$ne": nil}
+	}
+	if filter.Snapshot != "" {
+		query["snapshot"] = filter.Snapshot
+	}
+
+	if filter.IDs != nil {
+		// An id that cannot be stored matches nothing, so it is dropped.
+		ids := bson.A{}
+		for _, id := range filter.IDs {
+			if oid, err := bson.ObjectIDFromHex(id); err == nil {
+				ids = append(ids, oid)
+			}
+		}
+		query["_id"] = bson.M{"$in": ids}
+	}
+
+	return query
+}
+
+// levelValues lists the stored values for the given levels. Entries written
+// before levels existed have no level field and were all informational, so
+// null stands in for INFO.
+func levelValues(levels ...string) bson.A {
+	values := bson.A{}
+	for _, level := range levels {
+		values = append(values, level)
+		if level == types.DefaultLogLevel {
+			values = append(values, nil)
+		}
+	}
+	return values
+}
+
+// pageCursor is the position of the last entry of a page. It is handed to
+// clients as opaque base64 JSON.
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func encodeCursor(last types.Log) (string, error) {
+	data, err := json.Marshal(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(value string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, types.ErrInvalidCursor
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, types.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// filter matches entries strictly after the cursor in the given sort order.
+func (c *pageCursor) filter(order int) bson.A {
+	op := "$lt"
+	if order > 0 {
+		op = "$gt"
+	}
+
+	var id any = c.ID
+	if oid, err := bson.ObjectIDFromHex(c.ID); err == nil {
+		id = oid
+	}
+
+	return bson.A{
+		bson.M{"created_at": bson.M{op: c.CreatedAt}},
+		bson.M{"created_at": c.CreatedAt, "_id": bson.M{op: id}},
+	}
+}
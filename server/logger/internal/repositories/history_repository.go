@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"logger/types"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type historyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewHistoryRepository(db *mongo.Database) types.HistoryRepositoryInterface {
+	return &historyRepository{
+		collection: db.Collection("log_history"),
+	}
+}
+
+func (r *historyRepository) Add(ctx context.Context, version *types.LogVersion) error {
+	_, err := r.collection.InsertOne(ctx, version)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.ErrLogConflict
+	}
+	return err
+}
+
+func (r *historyRepository) FindVersions(ctx context.Context, logID string) ([]types.LogVersion, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"log_id": logID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := []types.LogVersion{}
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func (r *historyRepository) FindVersion(ctx context.Context, logID string, version int) (*types.LogVersion, error) {
+	var found types.LogVersion
+	err := r.collection.FindOne(ctx, bson.M{"log_id": logID, "version": version}).Decode(&found)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &found, nil
+}
+
+func (r *historyRepository) DeleteFor(ctx context.Context, logIDs []string) error {
+	if len(logIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.collection.DeleteMany(ctx, bson.M{"log_id": bson.M{"$in": logIDs}})
+	return err
+}
+
+// EnsureIndexes makes versions unique per entry, so two updates racing
+// from the same version cannot both record it.
+func (r *historyRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "log_id", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetName("log_id_version").SetUnique(true),
+	})
+	return err
+}
@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// appendFile is a file of JSON records, one per line. Each append is synced
+// before it returns, so an acknowledged record survives a crash.
+type appendFile struct {
+	path string
+	file journalFile
+}
+
+// journalFile is the part of *os.File an appendFile uses.
+type journalFile interface {
+	io.WriteCloser
+	Sync() error
+	Truncate(size int64) error
+	Stat() (os.FileInfo, error)
+}
+
+// openAppendFile calls load with every record already in the file, then
+// opens it for appending. A partial last line, left by a crash mid-write,
+// was never acknowledged and is cut off; any other unreadable record is an
+// error.
+func openAppendFile(path string, load func(record []byte) error) (*appendFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := replayFile(path, load); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appendFile{path: path, file: file}, nil
+}
+
+func replayFile(path string, load func(record []byte) error) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				log.Printf("Discarding incomplete record at the end of %s", path)
+				return file.Truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := load(line); err != nil {
+			return fmt.Errorf("corrupt record at byte %d: %w", offset, err)
+		}
+		offset += int64(len(line))
+	}
+}
+
+func (f *appendFile) append(record any) error {
+	if f.file == nil {
+		return fmt.Errorf("%s is closed", f.path)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.file.Write(append(data, '\n')); err != nil {
+		return f.rollback(info.Size(), err)
+	}
+	if err := f.file.Sync(); err != nil {
+		return f.rollback(info.Size(), err)
+	}
+	return nil
+}
+
+// rollback cuts off whatever part of a failed record reached the file, so
+// the next record starts on a line of its own.
+func (f *appendFile) rollback(size int64, cause error) error {
+	if err := f.file.Truncate(size); err != nil {
+		// The file may now end in a partial record; refuse further appends
+		// rather than join the next one onto it.
+		f.file.Close()
+		f.file = nil
+		return errors.Join(cause, fmt.Errorf("failed to roll back %s: %w", f.path, err))
+	}
+	return cause
+}
+
+// rewrite replaces the file with the records fill writes, then carries on
+// appending to the new file. The old file stays in place until the new one
+// is complete and synced.
+func (f *appendFile) rewrite(fill func(write func(record any) error) error) error {
+	temp := f.path + ".rewrite"
+	file, err := os.Create(temp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp)
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	if err := fill(encoder.Encode); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp, f.path); err != nil {
+		return err
+	}
+	return f.reopen()
+}
+
+// reopen switches to the file now at the path, after it has been replaced.
+func (f *appendFile) reopen() error {
+	if f.file != nil {
+		f.file.Close()
+	}
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		f.file = nil
+		return err
+	}
+	f.file = file
+	return nil
+}
+
+func (f *appendFile) close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// failingFile writes only part of the next record, or fails its sync, the
+// way a full disk would.
+type failingFile struct {
+	journalFile
+	shortWrite bool
+	failSync   bool
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if f.shortWrite {
+		n, _ := f.journalFile.Write(p[:len(p)/2])
+		return n, io.ErrShortWrite
+	}
+	return f.journalFile.Write(p)
+}
+
+func (f *failingFile) Sync() error {
+	if f.failSync {
+		return errors.New("sync failed")
+	}
+	return f.journalFile.Sync()
+}
+
+func TestAppendFileRollsBackFailedRecord(t *testing.T) {
+	cases := []struct {
+		name string
+		file failingFile
+	}{
+		{"short write", failingFile{shortWrite: true}},
+		{"failed sync", failingFile{failSync: true}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "records.ndjson")
+			noop := func([]byte) error { return nil }
+
+			f, err := openAppendFile(path, noop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := f.append(map[string]int{"n": 1}); err != nil {
+				t.Fatal(err)
+			}
+
+			failing := c.file
+			failing.journalFile = f.file
+			f.file = &failing
+			if err := f.append(map[string]string{"n": "a record long enough to be cut in half"}); err == nil {
+				t.Fatal("append through a failing file succeeded")
+			}
+
+			f.file = failing.journalFile
+			if err := f.append(map[string]int{"n": 3}); err != nil {
+				t.Fatal(err)
+			}
+			f.close()
+
+			var records []string
+			err = replayFile(path, func(record []byte) error {
+				records = append(records, string(record))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("replaying after a failed append: %v", err)
+			}
+			want := []string{"{\"n\":1}\n", "{\"n\":3}\n"}
+			if !slices.Equal(records, want) {
+				t.Errorf("records = %q, want %q", records, want)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"logger/types"
 	"time"
 
@@ -24,6 +26,7 @@ func (r *logRepository) Create(ctx context.Context, log *types.Log) error {
 	now := time.Now().UTC()
 	log.CreatedAt = now
 	log.UpdatedAt = now
+	log.DataText = types.FlattenText(log.Data)
 
 	result, err := r.collection.InsertOne(ctx, log)
 	if err != nil {
@@ -37,10 +40,50 @@ func (r *logRepository) Create(ctx context.Context, log *types.Log) error {
 	return nil
 }
 
-func (r *logRepository) FindAll(ctx context.Context) ([]types.Log, error) {
-	opts := options.Find().SetSort(bson.D{{"created_at", -1}})
+func (r *logRepository) CreateLogs(ctx context.Context, logs []*types.Log) ([]error, error) {
+	now := time.Now().UTC()
+	for _, log := range logs {
+		log.CreatedAt = now
+		log.UpdatedAt = now
+		log.DataText = types.FlattenText(log.Data)
+	}
+
+	result, err := r.collection.InsertMany(ctx, logs, options.InsertMany().SetOrdered(false))
+
+	errs := make([]error, len(logs))
+
+	var bulkErr mongo.BulkWriteException
+	if err != nil {
+		// Anything but per-document write errors leaves the outcome of
+		// the batch unknown.
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+			return nil, err
+		}
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Index >= 0 && writeErr.Index < len(errs) {
+				errs[writeErr.Index] = writeErr
+			}
+		}
+	}
+
+	// The driver assigns every _id before sending, so InsertedIDs lines up
+	// with logs even when some inserts failed.
+	for i, log := range logs {
+		if errs[i] != nil || result == nil || i >= len(result.InsertedIDs) {
+			continue
+		}
+		if oid, ok := result.InsertedIDs[i].(bson.ObjectID); ok {
+			log.ID = oid.Hex()
+		}
+	}
+
+	return errs, nil
+}
+
+func (r *logRepository) FindAll(ctx context.Context, filter types.LogFilter) ([]types.Log, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 	
-	cursor, err := r.collection.Find(ctx, bson.D{}, opts)
+	cursor, err := r.collection.Find(ctx, buildFilter(filter), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +101,117 @@ func (r *logRepository) FindAll(ctx context.Context) ([]types.Log, error) {
 	return logs, cursor.Err()
 }
 
-func (r *logRepository) FindByID(ctx context.Context, id string) (*types.Log, error) {
+// FindPage returns one page of logs using keyset pagination on
+// (created_at, _id), so deep pages cost the same as the first.
+func (r *logRepository) FindPage(ctx context.Context, query types.LogQuery) (*types.LogPage, error) {
+	filter := buildFilter(query.LogFilter)
+
+	order := -1
+	if query.Sort == types.SortAsc {
+		order = 1
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter["$or"] = after.filter(order)
+	}
+
+	// Fetch one extra entry to learn whether another page exists.
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(int64(query.Limit) + 1)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	logs := []types.Log{}
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	page := &types.LogPage{
+		Logs:  logs,
+		Limit: query.Limit,
+	}
+
+	if len(logs) > query.Limit {
+		page.Logs = logs[:query.Limit]
+		page.HasMore = true
+
+		last := page.Logs[len(page.Logs)-1]
+		next, err := encodeCursor(last)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+
+	return page, nil
+}
+
+func (r *logRepository) Search(ctx context.Context, query types.SearchQuery) (*types.SearchResult, error) {
+	filter := buildFilter(query.LogFilter)
+	filter["$text"] = bson.M{"$search": query.Text}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "created_at", Value: -1}}).
+		SetSkip(int64(query.Offset)).
+		SetLimit(int64(query.Limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	hits := []types.SearchHit{}
+	for cursor.Next(ctx) {
+		var hit types.SearchHit
+		if err := cursor.Decode(&hit.Log); err != nil {
+			return nil, err
+		}
+		if value, err := cursor.Current.LookupErr("score"); err == nil {
+			hit.Score, _ = value.DoubleOK()
+		}
+		hits = append(hits, hit)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return &types.SearchResult{
+		Hits:   hits,
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}, nil
+}
+
+// parseID reads a log ID, reporting a malformed one as ErrInvalidLogID
+// rather than as the driver's parse error.
+func parseID(id string) (bson.ObjectID, error) {
 	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return objectID, fmt.Errorf("%w %q", types.ErrInvalidLogID, id)
+	}
+	return objectID, nil
+}
+
+func (r *logRepository) FindByID(ctx context.Context, id string) (*types.Log, error) {
+	objectID, err := parseID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -78,19 +230,25 @@ func (r *logRepository) FindByID(ctx context.Context, id string) (*types.Log, er
 }
 
 func (r *logRepository) Update(ctx context.Context, id string, log *types.Log) error {
-	objectID, err := bson.ObjectIDFromHex(id)
+	objectID, err := parseID(id)
 	if err != nil {
 		return err
 	}
 
 	log.UpdatedAt = time.Now().UTC()
+	log.DataText = types.FlattenText(log.Data)
 
-	filter := bson.M{"_id": objectID}
+	filter := bson.M{"_id": objectID, "deleted_at": nil, "version": previousVersion(log.Version)}
 	update := bson.D{
-		{"$set", bson.D{
-			{"name", log.Name},
-			{"data", log.Data},
-			{"updated_at", log.UpdatedAt},
+		{Key: "$set", Value: bson.D{
+			{Key: "name", Value: log.Name},
+			{Key: "data", Value: log.Data},
+			{Key: "data_text", Value: log.DataText},
+			{Key: "level", Value: log.Level},
+			{Key: "tags", Value: log.Tags},
+			{Key: "attributes", Value: log.Attributes},
+			{Key: "version", Value: log.Version},
+			{Key: "updated_at", Value: log.UpdatedAt},
 		}},
 	}
 
@@ -100,42 +258,416 @@ func (r *logRepository) Update(ctx context.Context, id string, log *types.Log) e
 	}
 	
 	if result.MatchedCount == 0 {
-		return mongo.ErrNoDocuments
+		// Tell a missing entry from one another update moved on.
+		live, err := r.collection.CountDocuments(ctx, bson.M{"_id": objectID, "deleted_at": nil})
+		if err != nil {
+			return err
+		}
+		if live > 0 {
+			return types.ErrLogConflict
+		}
+		return types.ErrLogNotFound
 	}
 	
 	return nil
 }
 
+// previousVersion matches the stored version an update to version must
+// replace. Version 1 is stored as no version at all.
+func previousVersion(version int) any {
+	if version <= 2 {
+		return bson.M{"$in": bson.A{1, nil}}
+	}
+	return version - 1
+}
+
 func (r *logRepository) Delete(ctx context.Context, id string) error {
-	objectID, err := bson.ObjectIDFromHex(id)
+	objectID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": objectID, "deleted_at": nil}
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now().UTC()}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return types.ErrLogNotFound
+	}
+
+	return nil
+}
+
+func (r *logRepository) Trash(ctx context.Context, filter types.LogFilter, snapshot string) (int64, error) {
+	filter.Trash = false
+	update := bson.M{"$set": bson.M{
+		"deleted_at": time.Now().UTC(),
+		"snapshot":   snapshot,
+	}}
+
+	result, err := r.collection.UpdateMany(ctx, buildFilter(filter), update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *logRepository) Restore(ctx context.Context, id string) error {
+	objectID, err := parseID(id)
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objectID}
-	result, err := r.collection.DeleteOne(ctx, filter)
+	filter := bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}
+	result, err := r.collection.UpdateOne(ctx, filter, restoreUpdate)
 	if err != nil {
 		return err
 	}
-	
-	if result.DeletedCount == 0 {
-		return mongo.ErrNoDocuments
+
+	if result.MatchedCount == 0 {
+		return types.ErrLogNotFound
 	}
-	
+
 	return nil
 }
 
+func (r *logRepository) RestoreMany(ctx context.Context, filter types.LogFilter) (int64, error) {
+	filter.Trash = true
+
+	result, err := r.collection.UpdateMany(ctx, buildFilter(filter), restoreUpdate)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+var restoreUpdate = bson.M{"$unset": bson.M{"deleted_at": "", "snapshot": ""}}
+
 func (r *logRepository) DropCollection(ctx context.Context) error {
 	return r.collection.Drop(ctx)
 }
 
-func (r *logRepository) GetStats(ctx context.Context) (*types.LogStats, error) {
-	count, err := r.collection.CountDocuments(ctx, bson.D{})
+// EnsureIndexes creates the indexes the log queries rely on. Creating an
+// index that already exists is a no-op, so it is safe to run on every start.
+func (r *logRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// _id breaks ties between entries created in the same
+			// millisecond, which keyset pagination depends on.
+			Keys:    bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("created_at_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "level", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("level_created_at_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("name_created_at_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "service", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("service_created_at_id").SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "trace_id", Value: 1}},
+			Options: options.Index().SetName("trace_id").SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "request_id", Value: 1}},
+			Options: options.Index().SetName("request_id").SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "tags", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("tags_created_at"),
+		},
+		{
+			// Only trashed entries have deleted_at, so this stays small
+			// and serves the trash listing and purge.
+			Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+			Options: options.Index().SetName("deleted_at").SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "snapshot", Value: 1}},
+			Options: options.Index().SetName("snapshot").SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "name", Value: "text"}, {Key: "data_text", Value: "text"}},
+			Options: options.Index().
+				SetName("log_text").
+				SetWeights(bson.D{{Key: "name", Value: 3}, {Key: "data_text", Value: 1}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.backfillDataText(ctx)
+}
+
+// backfillDataText derives data_text for entries written before it existed
+// so they show up in search.
+func (r *logRepository) backfillDataText(ctx context.Context) error {
+	filter := bson.M{"data_text": bson.M{"$exists": false}}
+	opts := options.Find().SetProjection(bson.M{"data": 1})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var models []mongo.WriteModel
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		models = models[:0]
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID   bson.ObjectID `bson:"_id"`
+			Data any           `bson:"data"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"data_text": types.FlattenText(doc.Data)}}))
+
+		if len(models) == 500 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// topNames caps how many names GetStats reports.
+const topNames = 50
+
+func (r *logRepository) GetStats(ctx context.Context, filter types.LogFilter) (*types.LogStats, error) {
+	count := bson.M{"$sum": 1}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: buildFilter(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":   nil,
+					"total": count,
+					"first": bson.M{"$min": "$created_at"},
+					"last":  bson.M{"$max": "$created_at"},
+				}},
+			},
+			"by_level": bson.A{
+				// Entries from before levels existed count as INFO.
+				bson.M{"$group": bson.M{
+					"_id":   bson.M{"$ifNull": bson.A{"$level", types.DefaultLogLevel}},
+					"count": count,
+				}},
+			},
+			"by_name": bson.A{
+				bson.M{"$group": bson.M{"_id": "$name", "count": count}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": topNames},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Totals []struct {
+			Total int64     `bson:"total"`
+			First time.Time `bson:"first"`
+			Last  time.Time `bson:"last"`
+		} `bson:"totals"`
+		ByLevel []struct {
+			Level string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"by_level"`
+		ByName []types.NameCount `bson:"by_name"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
 		return nil, err
 	}
 
-	return &types.LogStats{
-		TotalLogs: count,
-	}, nil
-}
\ No newline at end of file
+	stats := &types.LogStats{
+		ByLevel: map[string]int64{},
+		ByName:  []types.NameCount{},
+	}
+	if len(result) == 0 {
+		return stats, nil
+	}
+
+	facets := result[0]
+	if len(facets.Totals) > 0 {
+		stats.TotalLogs = facets.Totals[0].Total
+		stats.FirstLogTime = facets.Totals[0].First
+		stats.LastLogTime = facets.Totals[0].Last
+	}
+	for _, level := range facets.ByLevel {
+		stats.ByLevel[level.Level] = level.Count
+	}
+	if facets.ByName != nil {
+		stats.ByName = facets.ByName
+	}
+
+	return stats, nil
+}
+
+// Histogram buckets with $dateTrunc and fills empty buckets with $densify,
+// so it needs MongoDB 5.1 or later.
+func (r *logRepository) Histogram(ctx context.Context, query types.HistogramQuery) ([]types.HistogramBucket, error) {
+	unit := string(query.Bucket)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: buildFilter(query.LogFilter)}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateTrunc": bson.M{
+				"date":     "$created_at",
+				"unit":     unit,
+				"timezone": "UTC",
+			}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$densify", Value: bson.M{
+			"field": "_id",
+			"range": bson.M{
+				"step":   1,
+				"unit":   unit,
+				"bounds": bson.A{query.From, query.To},
+			},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":   0,
+			"start": "$_id",
+			"count": bson.M{"$ifNull": bson.A{"$count", 0}},
+		}}},
+		{{Key: "$sort", Value: bson.M{"start": 1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []types.HistogramBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+func (r *logRepository) Stream(ctx context.Context, filter types.LogFilter, fn func(types.Log) error) error {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(500)
+
+	cursor, err := r.collection.Find(ctx, buildFilter(filter), opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var log types.Log
+		if err := cursor.Decode(&log); err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (r *logRepository) DeleteMany(ctx context.Context, filter types.LogFilter) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, buildFilter(filter))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// duplicateKey is the server error code for a unique index violation.
+const duplicateKey = 11000
+
+func (r *logRepository) Import(ctx context.Context, logs []types.Log) (int64, int64, error) {
+	if len(logs) == 0 {
+		return 0, 0, nil
+	}
+
+	docs := make([]bson.D, 0, len(logs))
+	for _, log := range logs {
+		var id any
+		if log.ID != "" {
+			oid, err := parseID(log.ID)
+			if err != nil {
+				return 0, 0, err
+			}
+			id = oid
+		} else {
+			id = bson.NewObjectID()
+		}
+
+		log.ID = ""
+		log.DataText = types.FlattenText(log.Data)
+
+		raw, err := bson.Marshal(log)
+		if err != nil {
+			return 0, 0, err
+		}
+		var doc bson.D
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return 0, 0, err
+		}
+		docs = append(docs, append(bson.D{{Key: "_id", Value: id}}, doc...))
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return int64(len(docs)), 0, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return 0, 0, err
+	}
+
+	var skipped int64
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != duplicateKey {
+			return 0, 0, err
+		}
+		skipped++
+	}
+
+	return int64(len(docs)) - skipped, skipped, nil
+}
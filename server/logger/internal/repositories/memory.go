@@ -0,0 +1,699 @@
+package repositories
+
+import (
+	"context"
+	"logger/types"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// change is one mutation of the store. Puts replace or insert whole
+// entries, so replaying changes in order rebuilds the same state.
+type change struct {
+	Drop    bool        `json:"drop,omitempty"`
+	Puts    []types.Log `json:"puts,omitempty"`
+	Deletes []string    `json:"deletes,omitempty"`
+}
+
+type memoryEntry struct {
+	log types.Log
+	// seq orders entries by insertion, which is what tails follow.
+	seq uint64
+}
+
+// memoryRepository keeps every entry in memory, sorted by (created_at, id).
+// It suits tests and small deployments; queries scan the whole store.
+type memoryRepository struct {
+	mu      sync.RWMutex
+	entries []*memoryEntry
+	byID    map[string]*memoryEntry
+	seq     uint64
+	// inserted is closed and replaced whenever an entry is added, waking
+	// any tails.
+	inserted chan struct{}
+	// journal, when set, must durably record a change before it is
+	// applied. A journal error leaves the store unchanged.
+	journal func(change) error
+}
+
+func NewMemoryRepository() types.LogRepositoryInterface {
+	return newMemoryRepository()
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{
+		byID:     make(map[string]*memoryEntry),
+		inserted: make(chan struct{}),
+	}
+}
+
+// commit journals and applies c. Callers hold the write lock.
+func (r *memoryRepository) commit(c change) error {
+	if r.journal != nil {
+		if err := r.journal(c); err != nil {
+			return err
+		}
+	}
+	r.apply(c)
+	return nil
+}
+
+func (r *memoryRepository) apply(c change) {
+	if c.Drop {
+		r.entries = nil
+		r.byID = make(map[string]*memoryEntry)
+	}
+
+	if len(c.Deletes) > 0 {
+		deleted := make(map[string]bool, len(c.Deletes))
+		for _, id := range c.Deletes {
+			if _, ok := r.byID[id]; ok {
+				deleted[id] = true
+				delete(r.byID, id)
+			}
+		}
+		r.entries = slices.DeleteFunc(r.entries, func(e *memoryEntry) bool {
+			return deleted[e.log.ID]
+		})
+	}
+
+	added := false
+	for _, log := range c.Puts {
+		log = cloneLog(log)
+		log.DataText = types.FlattenText(log.Data)
+
+		if existing, ok := r.byID[log.ID]; ok {
+			i := r.position(existing)
+			r.entries = slices.Delete(r.entries, i, i+1)
+			existing.log = log
+			r.insert(existing)
+			continue
+		}
+
+		r.seq++
+		entry := &memoryEntry{log: log, seq: r.seq}
+		r.byID[log.ID] = entry
+		r.insert(entry)
+		added = true
+	}
+
+	if added {
+		close(r.inserted)
+		r.inserted = make(chan struct{})
+	}
+}
+
+func (r *memoryRepository) insert(entry *memoryEntry) {
+	i := sort.Search(len(r.entries), func(i int) bool {
+		return !before(r.entries[i].log, entry.log)
+	})
+	r.entries = slices.Insert(r.entries, i, entry)
+}
+
+func (r *memoryRepository) position(entry *memoryEntry) int {
+	i := sort.Search(len(r.entries), func(i int) bool {
+		return !before(r.entries[i].log, entry.log)
+	})
+	for ; i < len(r.entries); i++ {
+		if r.entries[i] == entry {
+			return i
+		}
+	}
+	return slices.Index(r.entries, entry)
+}
+
+// before orders entries by (created_at, id). IDs are fixed-width hex, so
+// comparing them as strings matches ObjectID order.
+func before(a, b types.Log) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// cloneLog copies the slices and maps an entry owns so callers cannot
+// change stored entries. Data is shared; it is replaced, never modified.
+func cloneLog(log types.Log) types.Log {
+	log.Tags = slices.Clone(log.Tags)
+	log.Attributes = maps.Clone(log.Attributes)
+	return log
+}
+
+func validID(id string) error {
+	_, err := parseID(id)
+	return err
+}
+
+func (r *memoryRepository) Create(ctx context.Context, log *types.Log) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	if log.ID == "" {
+		log.ID = bson.NewObjectID().Hex()
+	}
+	log.CreatedAt = now
+	log.UpdatedAt = now
+	log.DataText = types.FlattenText(log.Data)
+
+	return r.commit(change{Puts: []types.Log{*log}})
+}
+
+func (r *memoryRepository) CreateLogs(ctx context.Context, logs []*types.Log) ([]error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	puts := make([]types.Log, 0, len(logs))
+	for _, log := range logs {
+		if log.ID == "" {
+			log.ID = bson.NewObjectID().Hex()
+		}
+		log.CreatedAt = now
+		log.UpdatedAt = now
+		log.DataText = types.FlattenText(log.Data)
+		puts = append(puts, *log)
+	}
+
+	if err := r.commit(change{Puts: puts}); err != nil {
+		return nil, err
+	}
+
+	return make([]error, len(logs)), nil
+}
+
+func (r *memoryRepository) Import(ctx context.Context, logs []types.Log) (int64, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var puts []types.Log
+	var skipped int64
+	seen := map[string]bool{}
+
+	for _, log := range logs {
+		if log.ID == "" {
+			log.ID = bson.NewObjectID().Hex()
+		} else if err := validID(log.ID); err != nil {
+			return 0, 0, err
+		}
+
+		if _, ok := r.byID[log.ID]; ok || seen[log.ID] {
+			skipped++
+			continue
+		}
+		seen[log.ID] = true
+		puts = append(puts, log)
+	}
+
+	if len(puts) == 0 {
+		return 0, skipped, nil
+	}
+	if err := r.commit(change{Puts: puts}); err != nil {
+		return 0, 0, err
+	}
+
+	return int64(len(puts)), skipped, nil
+}
+
+func (r *memoryRepository) FindByID(ctx context.Context, id string) (*types.Log, error) {
+	if err := validID(id); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.byID[id]
+	if !ok {
+		return nil, nil
+	}
+
+	log := cloneLog(entry.log)
+	return &log, nil
+}
+
+// Update and Delete report a missing entry as ErrLogNotFound, as the Mongo
+// repository does, so callers treat every backend alike.
+func (r *memoryRepository) Update(ctx context.Context, id string, log *types.Log) error {
+	if err := validID(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byID[id]
+	if !ok || entry.log.DeletedAt != nil {
+		return types.ErrLogNotFound
+	}
+	if entry.log.CurrentVersion() != max(log.Version, 2)-1 {
+		return types.ErrLogConflict
+	}
+
+	log.UpdatedAt = time.Now().UTC()
+	log.DataText = types.FlattenText(log.Data)
+
+	updated := entry.log
+	updated.Name = log.Name
+	updated.Data = log.Data
+	updated.Level = log.Level
+	updated.Tags = log.Tags
+	updated.Attributes = log.Attributes
+	updated.Version = log.Version
+	updated.UpdatedAt = log.UpdatedAt
+
+	return r.commit(change{Puts: []types.Log{updated}})
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	if err := validID(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byID[id]
+	if !ok || entry.log.DeletedAt != nil {
+		return types.ErrLogNotFound
+	}
+
+	deleted := cloneLog(entry.log)
+	now := time.Now().UTC()
+	deleted.DeletedAt = &now
+
+	return r.commit(change{Puts: []types.Log{deleted}})
+}
+
+func (r *memoryRepository) Trash(ctx context.Context, filter types.LogFilter, snapshot string) (int64, error) {
+	filter.Trash = false
+	now := time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var puts []types.Log
+	for _, entry := range r.entries {
+		if matchLog(filter, &entry.log) {
+			deleted := cloneLog(entry.log)
+			deleted.DeletedAt = &now
+			deleted.Snapshot = snapshot
+			puts = append(puts, deleted)
+		}
+	}
+	if len(puts) == 0 {
+		return 0, nil
+	}
+
+	if err := r.commit(change{Puts: puts}); err != nil {
+		return 0, err
+	}
+
+	return int64(len(puts)), nil
+}
+
+func (r *memoryRepository) Restore(ctx context.Context, id string) error {
+	if err := validID(id); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.byID[id]
+	if !ok || entry.log.DeletedAt == nil {
+		return types.ErrLogNotFound
+	}
+
+	restored := cloneLog(entry.log)
+	restored.DeletedAt = nil
+	restored.Snapshot = ""
+
+	return r.commit(change{Puts: []types.Log{restored}})
+}
+
+func (r *memoryRepository) RestoreMany(ctx context.Context, filter types.LogFilter) (int64, error) {
+	filter.Trash = true
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var puts []types.Log
+	for _, entry := range r.entries {
+		if matchLog(filter, &entry.log) {
+			restored := cloneLog(entry.log)
+			restored.DeletedAt = nil
+			restored.Snapshot = ""
+			puts = append(puts, restored)
+		}
+	}
+	if len(puts) == 0 {
+		return 0, nil
+	}
+
+	if err := r.commit(change{Puts: puts}); err != nil {
+		return 0, err
+	}
+
+	return int64(len(puts)), nil
+}
+
+func (r *memoryRepository) DeleteMany(ctx context.Context, filter types.LogFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for _, entry := range r.entries {
+		if matchLog(filter, &entry.log) {
+			ids = append(ids, entry.log.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := r.commit(change{Deletes: ids}); err != nil {
+		return 0, err
+	}
+
+	return int64(len(ids)), nil
+}
+
+func (r *memoryRepository) DropCollection(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.commit(change{Drop: true})
+}
+
+// EnsureIndexes has nothing to build; every query scans.
+func (r *memoryRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+// matching returns copies of the entries matching filter in ascending
+// (created_at, id) order.
+func (r *memoryRepository) matching(filter types.LogFilter) []types.Log {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logs := []types.Log{}
+	for _, entry := range r.entries {
+		if matchLog(filter, &entry.log) {
+			logs = append(logs, cloneLog(entry.log))
+		}
+	}
+	return logs
+}
+
+func (r *memoryRepository) FindAll(ctx context.Context, filter types.LogFilter) ([]types.Log, error) {
+	logs := r.matching(filter)
+	slices.Reverse(logs)
+	return logs, nil
+}
+
+func (r *memoryRepository) FindPage(ctx context.Context, query types.LogQuery) (*types.LogPage, error) {
+	logs := r.matching(query.LogFilter)
+	if query.Sort != types.SortAsc {
+		slices.Reverse(logs)
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		mark := types.Log{CreatedAt: after.CreatedAt, ID: after.ID}
+
+		start := len(logs)
+		for i, log := range logs {
+			if (query.Sort == types.SortAsc && before(mark, log)) || (query.Sort != types.SortAsc && before(log, mark)) {
+				start = i
+				break
+			}
+		}
+		logs = logs[start:]
+	}
+
+	page := &types.LogPage{
+		Logs:  logs,
+		Limit: query.Limit,
+	}
+
+	if len(logs) > query.Limit {
+		page.Logs = logs[:query.Limit]
+		page.HasMore = true
+
+		next, err := encodeCursor(page.Logs[len(page.Logs)-1])
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+
+	return page, nil
+}
+
+// Search approximates MongoDB's $text search: an entry matches if any
+// term appears in its name or data, terms starting with - exclude entries,
+// and name matches weigh three times as much as data matches.
+func (r *memoryRepository) Search(ctx context.Context, query types.SearchQuery) (*types.SearchResult, error) {
+	var include, exclude []string
+	for _, term := range strings.Fields(strings.ToLower(query.Text)) {
+		term = strings.Trim(term, `"`)
+		if negated, ok := strings.CutPrefix(term, "-"); ok {
+			if negated != "" {
+				exclude = append(exclude, negated)
+			}
+		} else if term != "" {
+			include = append(include, term)
+		}
+	}
+
+	hits := []types.SearchHit{}
+	for _, log := range r.matching(query.LogFilter) {
+		name := strings.ToLower(log.Name)
+		data := strings.ToLower(log.DataText)
+
+		excluded := false
+		for _, term := range exclude {
+			if strings.Contains(name, term) || strings.Contains(data, term) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+
+		var score float64
+		for _, term := range include {
+			score += 3*float64(strings.Count(name, term)) + float64(strings.Count(data, term))
+		}
+		if score > 0 {
+			hits = append(hits, types.SearchHit{Log: log, Score: score})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Log.CreatedAt.After(hits[j].Log.CreatedAt)
+	})
+
+	result := &types.SearchResult{
+		Hits:   []types.SearchHit{},
+		Total:  int64(len(hits)),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if query.Offset < len(hits) {
+		end := min(query.Offset+query.Limit, len(hits))
+		result.Hits = hits[query.Offset:end]
+	}
+
+	return result, nil
+}
+
+func (r *memoryRepository) GetStats(ctx context.Context, filter types.LogFilter) (*types.LogStats, error) {
+	stats := &types.LogStats{
+		ByLevel: map[string]int64{},
+		ByName:  []types.NameCount{},
+	}
+
+	names := map[string]int64{}
+	for _, log := range r.matching(filter) {
+		if stats.TotalLogs == 0 {
+			stats.FirstLogTime = log.CreatedAt
+		}
+		stats.TotalLogs++
+		stats.LastLogTime = log.CreatedAt
+
+		level := log.Level
+		if level == "" {
+			level = types.DefaultLogLevel
+		}
+		stats.ByLevel[level]++
+		names[log.Name]++
+	}
+
+	for name, count := range names {
+		stats.ByName = append(stats.ByName, types.NameCount{Name: name, Count: count})
+	}
+	sort.Slice(stats.ByName, func(i, j int) bool {
+		if stats.ByName[i].Count != stats.ByName[j].Count {
+			return stats.ByName[i].Count > stats.ByName[j].Count
+		}
+		return stats.ByName[i].Name < stats.ByName[j].Name
+	})
+	if len(stats.ByName) > topNames {
+		stats.ByName = stats.ByName[:topNames]
+	}
+
+	return stats, nil
+}
+
+func (r *memoryRepository) Histogram(ctx context.Context, query types.HistogramQuery) ([]types.HistogramBucket, error) {
+	size := query.Bucket.Duration()
+	if size == 0 {
+		return nil, types.ErrInvalidQuery
+	}
+
+	counts := map[time.Time]int64{}
+	for _, log := range r.matching(query.LogFilter) {
+		counts[log.CreatedAt.UTC().Truncate(size)]++
+	}
+
+	buckets := []types.HistogramBucket{}
+	for start := query.From.UTC().Truncate(size); start.Before(query.To); start = start.Add(size) {
+		buckets = append(buckets, types.HistogramBucket{Start: start, Count: counts[start]})
+	}
+
+	return buckets, nil
+}
+
+func (r *memoryRepository) Stream(ctx context.Context, filter types.LogFilter, fn func(types.Log) error) error {
+	for _, log := range r.matching(filter) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tail follows insertion order rather than created_at, so imported entries
+// with old timestamps are delivered too.
+func (r *memoryRepository) Tail(ctx context.Context, query types.TailQuery, fn func(types.Log) error) error {
+	r.mu.RLock()
+	last := r.seq
+	if query.AfterID != "" {
+		entry, ok := r.byID[query.AfterID]
+		if !ok {
+			r.mu.RUnlock()
+			return types.ErrInvalidCursor
+		}
+		last = entry.seq
+	}
+	r.mu.RUnlock()
+
+	for {
+		// Entries are copied under the lock; an update replaces an
+		// entry's log in place.
+		r.mu.RLock()
+		var pending []memoryEntry
+		for _, entry := range r.entries {
+			if entry.seq > last && matchLog(query.LogFilter, &entry.log) {
+				pending = append(pending, memoryEntry{log: cloneLog(entry.log), seq: entry.seq})
+			}
+		}
+		seq := r.seq
+		inserted := r.inserted
+		r.mu.RUnlock()
+
+		sort.Slice(pending, func(i, j int) bool { return pending[i].seq < pending[j].seq })
+		for _, entry := range pending {
+			if err := fn(entry.log); err != nil {
+				return err
+			}
+		}
+		last = seq
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-inserted:
+		}
+	}
+}
+
+// matchLog is buildFilter evaluated in Go.
+func matchLog(filter types.LogFilter, log *types.Log) bool {
+	level := log.Level
+	if level == "" {
+		level = types.DefaultLogLevel
+	}
+	if filter.Level != "" && level != filter.Level {
+		return false
+	}
+	if slices.Contains(filter.ExcludeLevels, level) {
+		return false
+	}
+
+	if len(filter.Names) > 0 && !slices.Contains(filter.Names, log.Name) {
+		return false
+	}
+	if slices.Contains(filter.ExcludeNames, log.Name) {
+		return false
+	}
+
+	if !filter.From.IsZero() && log.CreatedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !log.CreatedAt.Before(filter.To) {
+		return false
+	}
+
+	if filter.Service != "" && log.Service != filter.Service {
+		return false
+	}
+	if filter.RequestID != "" && log.RequestID != filter.RequestID {
+		return false
+	}
+	if filter.TraceID != "" && log.TraceID != filter.TraceID {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !slices.Contains(log.Tags, tag) {
+			return false
+		}
+	}
+
+	if filter.Search != "" && !strings.Contains(strings.ToLower(log.DataText), strings.ToLower(filter.Search)) {
+		return false
+	}
+
+	if filter.Trash != (log.DeletedAt != nil) {
+		return false
+	}
+	if filter.Trash && !filter.DeletedBefore.IsZero() && !log.DeletedAt.Before(filter.DeletedBefore) {
+		return false
+	}
+	if filter.Snapshot != "" && log.Snapshot != filter.Snapshot {
+		return false
+	}
+	if filter.IDs != nil && !slices.Contains(filter.IDs, log.ID) {
+		return false
+	}
+
+	return true
+}
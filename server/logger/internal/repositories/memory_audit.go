@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"logger/types"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// memoryAuditRepository keeps the audit trail in memory, in Seq order.
+type memoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []types.AuditEntry
+	// journal, when set, must durably record an entry before it is added.
+	journal func(types.AuditEntry) error
+}
+
+func NewMemoryAuditRepository() types.AuditRepositoryInterface {
+	return &memoryAuditRepository{}
+}
+
+func cloneAuditEntry(entry types.AuditEntry) types.AuditEntry {
+	entry.Tags = slices.Clone(entry.Tags)
+	entry.Attributes = maps.Clone(entry.Attributes)
+	return entry
+}
+
+func (r *memoryAuditRepository) Last(ctx context.Context) (*types.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.entries) == 0 {
+		return nil, nil
+	}
+
+	entry := cloneAuditEntry(r.entries[len(r.entries)-1])
+	return &entry, nil
+}
+
+func (r *memoryAuditRepository) Append(ctx context.Context, entry *types.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n := len(r.entries); n > 0 && entry.Seq <= r.entries[n-1].Seq {
+		return types.ErrAuditConflict
+	}
+
+	if r.journal != nil {
+		if err := r.journal(*entry); err != nil {
+			return err
+		}
+	}
+
+	r.entries = append(r.entries, cloneAuditEntry(*entry))
+	return nil
+}
+
+// add appends an entry read back from a journal.
+func (r *memoryAuditRepository) add(entry types.AuditEntry) {
+	r.entries = append(r.entries, entry)
+}
+
+func (r *memoryAuditRepository) search(seq int64) int {
+	return sort.Search(len(r.entries), func(i int) bool {
+		return r.entries[i].Seq >= seq
+	})
+}
+
+func (r *memoryAuditRepository) FindBySeq(ctx context.Context, seq int64) (*types.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	i := r.search(seq)
+	if i == len(r.entries) || r.entries[i].Seq != seq {
+		return nil, nil
+	}
+
+	entry := cloneAuditEntry(r.entries[i])
+	return &entry, nil
+}
+
+func (r *memoryAuditRepository) FindAfter(ctx context.Context, after int64, limit int) ([]types.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start := r.search(after + 1)
+	end := min(start+limit, len(r.entries))
+
+	entries := make([]types.AuditEntry, 0, end-start)
+	for _, entry := range r.entries[start:end] {
+		entries = append(entries, cloneAuditEntry(entry))
+	}
+
+	return entries, nil
+}
+
+// Walk reads the trail in chunks so fn runs without the lock held. It goes
+// by position rather than Seq so a tampered file, with entries out of
+// order, is still walked as stored and the break is found.
+func (r *memoryAuditRepository) Walk(ctx context.Context, fn func(types.AuditEntry) error) error {
+	for start := 0; ; start += 500 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.mu.RLock()
+		end := min(start+500, len(r.entries))
+		var entries []types.AuditEntry
+		if start < end {
+			entries = make([]types.AuditEntry, 0, end-start)
+			for _, entry := range r.entries[start:end] {
+				entries = append(entries, cloneAuditEntry(entry))
+			}
+		}
+		r.mu.RUnlock()
+
+		if len(entries) == 0 {
+			return nil
+		}
+
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"logger/types"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// memoryHistoryRepository keeps each entry's versions in memory, in
+// version order.
+type memoryHistoryRepository struct {
+	mu       sync.RWMutex
+	versions map[string][]types.LogVersion
+	// journal, when set, must durably record a version before it is added.
+	journal func(types.LogVersion) error
+}
+
+func NewMemoryHistoryRepository() types.HistoryRepositoryInterface {
+	return newMemoryHistoryRepository()
+}
+
+func newMemoryHistoryRepository() *memoryHistoryRepository {
+	return &memoryHistoryRepository{
+		versions: make(map[string][]types.LogVersion),
+	}
+}
+
+func cloneVersion(version types.LogVersion) types.LogVersion {
+	version.Tags = slices.Clone(version.Tags)
+	version.Attributes = maps.Clone(version.Attributes)
+	version.Diff = slices.Clone(version.Diff)
+	return version
+}
+
+func (r *memoryHistoryRepository) Add(ctx context.Context, version *types.LogVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.versions[version.LogID] {
+		if existing.Version == version.Version {
+			return types.ErrLogConflict
+		}
+	}
+
+	if r.journal != nil {
+		if err := r.journal(*version); err != nil {
+			return err
+		}
+	}
+
+	r.add(cloneVersion(*version))
+	return nil
+}
+
+// add inserts a version in order. Callers hold the write lock.
+func (r *memoryHistoryRepository) add(version types.LogVersion) {
+	versions := r.versions[version.LogID]
+	i, _ := slices.BinarySearchFunc(versions, version.Version, func(v types.LogVersion, n int) int {
+		return v.Version - n
+	})
+	r.versions[version.LogID] = slices.Insert(versions, i, version)
+}
+
+func (r *memoryHistoryRepository) FindVersions(ctx context.Context, logID string) ([]types.LogVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := make([]types.LogVersion, 0, len(r.versions[logID]))
+	for _, version := range r.versions[logID] {
+		versions = append(versions, cloneVersion(version))
+	}
+
+	return versions, nil
+}
+
+func (r *memoryHistoryRepository) FindVersion(ctx context.Context, logID string, version int) (*types.LogVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, existing := range r.versions[logID] {
+		if existing.Version == version {
+			found := cloneVersion(existing)
+			return &found, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *memoryHistoryRepository) DeleteFor(ctx context.Context, logIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range logIDs {
+		delete(r.versions, id)
+	}
+
+	return nil
+}
+
+func (r *memoryHistoryRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"logger/types"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	// errChangeStreamUnsupported is returned by standalone servers, which
+	// have no oplog to watch.
+	errChangeStreamUnsupported = 40573
+
+	tailPollInterval = time.Second
+	tailBatchSize    = 500
+)
+
+// Tail calls fn for each entry after AfterID and then for every new entry
+// until ctx ends or fn fails. It watches a change stream where the server
+// supports one and polls otherwise.
+func (r *logRepository) Tail(ctx context.Context, query types.TailQuery, fn func(types.Log) error) error {
+	var after *pageCursor
+	if query.AfterID != "" {
+		last, err := r.FindByID(ctx, query.AfterID)
+		if err != nil {
+			return err
+		}
+		if last == nil {
+			return types.ErrInvalidCursor
+		}
+		after = &pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	// Open the stream before catching up so nothing inserted in between is
+	// missed; the catch-up entries it also sees are skipped.
+	stream, err := r.watch(ctx, query.LogFilter)
+	if err != nil {
+		var serverErr mongo.ServerError
+		if errors.As(err, &serverErr) && serverErr.HasErrorCode(errChangeStreamUnsupported) {
+			return r.poll(ctx, query.LogFilter, after, fn)
+		}
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	seen := map[string]bool{}
+	if after != nil {
+		_, err := r.catchUp(ctx, query.LogFilter, after, func(log types.Log) error {
+			seen[log.ID] = true
+			return fn(log)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for stream.Next(ctx) {
+		var event struct {
+			FullDocument types.Log `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+
+		if seen[event.FullDocument.ID] {
+			delete(seen, event.FullDocument.ID)
+			continue
+		}
+		if err := fn(event.FullDocument); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return stream.Err()
+}
+
+func (r *logRepository) watch(ctx context.Context, filter types.LogFilter) (*mongo.ChangeStream, error) {
+	match := bson.M{"operationType": "insert"}
+	for key, value := range buildFilter(filter) {
+		match["fullDocument."+key] = value
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	return r.collection.Watch(ctx, pipeline)
+}
+
+// poll is the fallback for standalone servers. Entries are read in
+// (created_at, _id) order, so one committed with an older timestamp after
+// a later one was already read is not delivered.
+func (r *logRepository) poll(ctx context.Context, filter types.LogFilter, after *pageCursor, fn func(types.Log) error) error {
+	if after == nil {
+		// Start from the newest existing entry rather than replaying
+		// the whole collection.
+		opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+		var last types.Log
+		err := r.collection.FindOne(ctx, buildFilter(filter), opts).Decode(&last)
+		switch {
+		case err == nil:
+			after = &pageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		case errors.Is(err, mongo.ErrNoDocuments):
+			after = &pageCursor{CreatedAt: time.Now().UTC()}
+		default:
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	for {
+		next, err := r.catchUp(ctx, filter, after, fn)
+		if err != nil {
+			return err
+		}
+		after = next
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// catchUp delivers every entry after the cursor in ascending order and
+// returns the position of the last one delivered.
+func (r *logRepository) catchUp(ctx context.Context, filter types.LogFilter, after *pageCursor, fn func(types.Log) error) (*pageCursor, error) {
+	for {
+		query := buildFilter(filter)
+		if after.ID == "" {
+			createdAt, _ := query["created_at"].(bson.M)
+			if createdAt == nil {
+				createdAt = bson.M{}
+			}
+			createdAt["$gt"] = after.CreatedAt
+			query["created_at"] = createdAt
+		} else {
+			query["$or"] = after.filter(1)
+		}
+
+		opts := options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+			SetLimit(tailBatchSize)
+
+		cursor, err := r.collection.Find(ctx, query, opts)
+		if err != nil {
+			return after, err
+		}
+
+		logs := []types.Log{}
+		err = cursor.All(ctx, &logs)
+		cursor.Close(ctx)
+		if err != nil {
+			return after, err
+		}
+
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return after, err
+			}
+			after = &pageCursor{CreatedAt: log.CreatedAt, ID: log.ID}
+		}
+
+		if len(logs) < tailBatchSize {
+			return after, nil
+		}
+	}
+}
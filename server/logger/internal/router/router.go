@@ -9,12 +9,14 @@ import (
 )
 
 type App struct {
-	logHandler *handlers.LogHandler
+	logHandler   *handlers.LogHandler
+	auditHandler *handlers.AuditHandler
 }
 
-func NewApp(logHandler *handlers.LogHandler) *App {
+func NewApp(logHandler *handlers.LogHandler, auditHandler *handlers.AuditHandler) *App {
 	return &App{
-		logHandler: logHandler,
+		logHandler:   logHandler,
+		auditHandler: auditHandler,
 	}
 }
 
@@ -38,11 +40,33 @@ func (a *App) setupV1Routes(router *mux.Router) {
 	logs := router.PathPrefix("/logs").Subrouter()
 	logs.HandleFunc("", a.logHandler.GetAllLogs).Methods("GET")
 	logs.HandleFunc("", a.logHandler.CreateLog).Methods("POST")
+
+	// Fixed paths must be registered before /{id}, which would match them.
+	logs.HandleFunc("/bulk", a.logHandler.CreateLogs).Methods("POST")
+	logs.HandleFunc("/stats", a.logHandler.GetLogsStats).Methods("GET")
+	logs.HandleFunc("/search", a.logHandler.SearchLogs).Methods("GET")
+	logs.HandleFunc("/stream", a.logHandler.StreamLogs).Methods("GET")
+	logs.HandleFunc("/export", a.logHandler.ExportLogs).Methods("GET")
+	logs.HandleFunc("/import", a.logHandler.ImportLogs).Methods("POST")
+	logs.HandleFunc("/histogram", a.logHandler.GetLogsHistogram).Methods("GET")
+	logs.HandleFunc("/trash", a.logHandler.GetTrash).Methods("GET")
+	logs.HandleFunc("/trash/restore", a.logHandler.RestoreSnapshot).Methods("POST")
+	logs.HandleFunc("/drop", a.logHandler.DropAllLogs).Methods("DELETE")
+
 	logs.HandleFunc("/{id}", a.logHandler.GetLogByID).Methods("GET")
 	logs.HandleFunc("/{id}", a.logHandler.UpdateLog).Methods("PUT")
 	logs.HandleFunc("/{id}", a.logHandler.DeleteLog).Methods("DELETE")
-	
-	logs.HandleFunc("/stats", a.logHandler.GetLogsStats).Methods("GET")
-	logs.HandleFunc("/drop", a.logHandler.DropAllLogs).Methods("DELETE").
-		Queries("confirm", "true") 
+	logs.HandleFunc("/{id}/restore", a.logHandler.RestoreLog).Methods("POST")
+	logs.HandleFunc("/{id}/history", a.logHandler.GetLogHistory).Methods("GET")
+	logs.HandleFunc("/{id}/revert", a.logHandler.RevertLog).Methods("POST")
+
+	// The audit trail is append-only: changes are refused outright rather
+	// than left to fall through to a generic 405.
+	audit := router.PathPrefix("/audit").Subrouter()
+	audit.HandleFunc("", a.auditHandler.GetAuditEntries).Methods("GET")
+	audit.HandleFunc("", a.auditHandler.CreateAuditEntry).Methods("POST")
+	audit.HandleFunc("", a.auditHandler.RejectAuditChange).Methods("DELETE")
+	audit.HandleFunc("/verify", a.auditHandler.VerifyAudit).Methods("GET")
+	audit.HandleFunc("/{seq}", a.auditHandler.GetAuditEntry).Methods("GET")
+	audit.HandleFunc("/{seq}", a.auditHandler.RejectAuditChange).Methods("PUT", "PATCH", "DELETE")
 }
\ No newline at end of file
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"logger/types"
+	"sync"
+	"time"
+)
+
+// maxAuditAttempts bounds how often an append is retried when another
+// instance extends the chain first.
+const maxAuditAttempts = 5
+
+// errStopWalk ends a walk early without it counting as a failure.
+var errStopWalk = errors.New("stop walk")
+
+type AuditService struct {
+	repo types.AuditRepositoryInterface
+	// mu serializes appends from this instance; the repository's unique
+	// sequence numbers catch races with other instances.
+	mu sync.Mutex
+}
+
+func NewAuditService(repo types.AuditRepositoryInterface) types.AuditServiceInterface {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// AppendAudit validates the entry like a log entry and adds it to the head
+// of the chain.
+func (s *AuditService) AppendAudit(ctx context.Context, req types.CreateLogRequest) (*types.AuditEntry, error) {
+	log, err := newLog(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < maxAuditAttempts; attempt++ {
+		head, err := s.repo.Last(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &types.AuditEntry{
+			Seq:        1,
+			Name:       log.Name,
+			Data:       log.Data,
+			Level:      log.Level,
+			Service:    log.Service,
+			Host:       log.Host,
+			RequestID:  log.RequestID,
+			TraceID:    log.TraceID,
+			Tags:       log.Tags,
+			Attributes: log.Attributes,
+			CreatedAt:  time.Now().UTC().Truncate(time.Millisecond),
+		}
+		if head != nil {
+			entry.Seq = head.Seq + 1
+			entry.PrevHash = head.Hash
+		}
+
+		entry.Hash, err = entry.ComputeHash()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", types.ErrInvalidLog, err)
+		}
+
+		err = s.repo.Append(ctx, entry)
+		if errors.Is(err, types.ErrAuditConflict) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return entry, nil
+	}
+
+	return nil, fmt.Errorf("%w after %d attempts", types.ErrAuditConflict, maxAuditAttempts)
+}
+
+func (s *AuditService) GetAuditEntry(ctx context.Context, seq int64) (*types.AuditEntry, error) {
+	if seq < 1 {
+		return nil, fmt.Errorf("%w: audit sequence numbers start at 1", types.ErrInvalidQuery)
+	}
+
+	entry, err := s.repo.FindBySeq(ctx, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		return nil, types.ErrAuditNotFound
+	}
+
+	return entry, nil
+}
+
+func (s *AuditService) ListAudit(ctx context.Context, query types.AuditQuery) (*types.AuditPage, error) {
+	if query.After < 0 {
+		return nil, fmt.Errorf("%w: after must not be negative", types.ErrInvalidQuery)
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = types.DefaultPageSize
+	case query.Limit < 0:
+		return nil, fmt.Errorf("%w: limit must be positive", types.ErrInvalidQuery)
+	case query.Limit > types.MaxPageSize:
+		query.Limit = types.MaxPageSize
+	}
+
+	// One extra entry tells whether there is another page.
+	entries, err := s.repo.FindAfter(ctx, query.After, query.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &types.AuditPage{
+		Entries: entries,
+		Limit:   query.Limit,
+	}
+	if len(entries) > query.Limit {
+		page.Entries = entries[:query.Limit]
+		page.HasMore = true
+		page.NextAfter = page.Entries[query.Limit-1].Seq
+	}
+
+	return page, nil
+}
+
+// VerifyAudit recomputes every hash in order. The chain breaks where an
+// entry is missing, where PrevHash does not match the entry before, or
+// where the stored hash does not match the content.
+func (s *AuditService) VerifyAudit(ctx context.Context) (*types.AuditVerification, error) {
+	result := &types.AuditVerification{Valid: true}
+
+	fail := func(seq int64, reason string) error {
+		result.Valid = false
+		result.Break = &types.AuditBreak{Seq: seq, Reason: reason}
+		return errStopWalk
+	}
+
+	err := s.repo.Walk(ctx, func(entry types.AuditEntry) error {
+		expected := result.HeadSeq + 1
+		if entry.Seq != expected {
+			return fail(expected, fmt.Sprintf("entry %d is missing; the next entry is %d", expected, entry.Seq))
+		}
+
+		if entry.PrevHash != result.HeadHash {
+			if expected == 1 {
+				return fail(entry.Seq, "the first entry has a previous hash")
+			}
+			return fail(entry.Seq, fmt.Sprintf("prev_hash does not match the hash of entry %d", result.HeadSeq))
+		}
+
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			return fail(entry.Seq, "content cannot be hashed: "+err.Error())
+		}
+		if hash != entry.Hash {
+			return fail(entry.Seq, "hash does not match the entry's content")
+		}
+
+		result.Checked++
+		result.HeadSeq = entry.Seq
+		result.HeadHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return nil, err
+	}
+
+	return result, nil
+}
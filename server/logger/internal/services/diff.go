@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/json"
+	"logger/types"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// editable is the part of an entry an update can change, as it is
+// diffed and recorded in the history.
+type editable struct {
+	Name       string                 `json:"name"`
+	Data       interface{}            `json:"data"`
+	Level      string                 `json:"level"`
+	Tags       []string               `json:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+func editableFields(log *types.Log) editable {
+	return editable{
+		Name:       log.Name,
+		Data:       log.Data,
+		Level:      log.Level,
+		Tags:       log.Tags,
+		Attributes: log.Attributes,
+	}
+}
+
+// diffLogs describes how to turn before into after as JSON Patch
+// operations. Objects are compared key by key so a change deep in data is
+// reported at its own path; arrays and scalars are replaced whole.
+func diffLogs(before, after *types.Log) ([]types.PatchOp, error) {
+	from, err := toJSONValue(editableFields(before))
+	if err != nil {
+		return nil, err
+	}
+	to, err := toJSONValue(editableFields(after))
+	if err != nil {
+		return nil, err
+	}
+
+	ops := []types.PatchOp{}
+	diffValues("", from, to, &ops)
+	return ops, nil
+}
+
+// toJSONValue converts v to the generic form encoding/json decodes into,
+// so values compare alike whether they came from a request or a database.
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diffValues(path string, from, to any, ops *[]types.PatchOp) {
+	fromObject, fromIsObject := from.(map[string]any)
+	toObject, toIsObject := to.(map[string]any)
+
+	if !fromIsObject || !toIsObject {
+		if !reflect.DeepEqual(from, to) {
+			*ops = append(*ops, types.PatchOp{Op: "replace", Path: path, Value: to, Old: from})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(fromObject)+len(toObject))
+	for key := range fromObject {
+		keys = append(keys, key)
+	}
+	for key := range toObject {
+		if _, ok := fromObject[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		child := path + "/" + escapePointer(key)
+		oldValue, inFrom := fromObject[key]
+		newValue, inTo := toObject[key]
+
+		switch {
+		case !inFrom:
+			*ops = append(*ops, types.PatchOp{Op: "add", Path: child, Value: newValue})
+		case !inTo:
+			*ops = append(*ops, types.PatchOp{Op: "remove", Path: child, Old: oldValue})
+		default:
+			diffValues(child, oldValue, newValue, ops)
+		}
+	}
+}
+
+// escapePointer escapes a key for use in a JSON Pointer (RFC 6901).
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	snippetContext = 40
+	maxSnippets    = 3
+)
+
+// highlighter marks search terms in text. Mongo's text search stems words,
+// so any word starting with a term is marked, which covers the common
+// suffixes ("fail" marks "failed" and "failures").
+type highlighter struct {
+	pattern *regexp.Regexp
+}
+
+func newHighlighter(query string) *highlighter {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		// Negated terms never appear in a hit.
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		term := strings.Trim(field, `"'`)
+		if term != "" {
+			terms = append(terms, regexp.QuoteMeta(term))
+		}
+	}
+
+	if len(terms) == 0 {
+		return &highlighter{}
+	}
+
+	return &highlighter{
+		pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(terms, "|") + `)\w*`),
+	}
+}
+
+// snippets returns up to maxSnippets excerpts of text around matches, HTML
+// escaped, with each match wrapped in <mark>.
+func (h *highlighter) snippets(text string) []string {
+	if h.pattern == nil || text == "" {
+		return nil
+	}
+
+	matches := h.pattern.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var snippets []string
+
+	for i := 0; i < len(matches) && len(snippets) < maxSnippets; {
+		start := backToRune(text, max(matches[i][0]-snippetContext, 0))
+		end := min(matches[i][1]+snippetContext, len(text))
+
+		// Fold matches that fall inside this window into the same snippet.
+		j := i + 1
+		for j < len(matches) && matches[j][0] < end {
+			end = min(max(end, matches[j][1]+snippetContext), len(text))
+			j++
+		}
+		end = backToRune(text, end)
+
+		var b strings.Builder
+		if start > 0 {
+			b.WriteString("…")
+		}
+
+		pos := start
+		for _, m := range matches[i:j] {
+			if m[1] > end {
+				break
+			}
+			b.WriteString(html.EscapeString(text[pos:m[0]]))
+			b.WriteString("<mark>")
+			b.WriteString(html.EscapeString(text[m[0]:m[1]]))
+			b.WriteString("</mark>")
+			pos = m[1]
+		}
+		b.WriteString(html.EscapeString(text[pos:end]))
+
+		if end < len(text) {
+			b.WriteString("…")
+		}
+
+		snippets = append(snippets, b.String())
+		i = j
+	}
+
+	return snippets
+}
+
+// backToRune moves i back to the start of the rune it falls in.
+func backToRune(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -2,28 +2,123 @@ package services
 
 import (
 	"context"
-	"errors"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"logger/types"
+	"slices"
+	"strings"
 	"time"
 )
 
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// defaultHistogramBuckets sets the range when from is not given.
+	defaultHistogramBuckets = 24
+)
+
 type LogService struct {
-	repo types.LogRepositoryInterface
+	repo    types.LogRepositoryInterface
+	history types.HistoryRepositoryInterface
 }
 
-func NewLogService(repo types.LogRepositoryInterface) types.LogServiceInterface {
+func NewLogService(repo types.LogRepositoryInterface, history types.HistoryRepositoryInterface) types.LogServiceInterface {
 	return &LogService{
-		repo: repo,
+		repo:    repo,
+		history: history,
 	}
 }
 
-func (s *LogService) GetAllLogs(ctx context.Context) ([]types.Log, error) {
-	return s.repo.FindAll(ctx)
+func (s *LogService) GetLogs(ctx context.Context, query types.LogQuery) (*types.LogPage, error) {
+	if query.Level != "" {
+		level, err := normalizeLevel(query.Level)
+		if err != nil {
+			return nil, err
+		}
+		query.Level = level
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = types.DefaultPageSize
+	case query.Limit < 0:
+		return nil, fmt.Errorf("%w: limit must be positive", types.ErrInvalidQuery)
+	case query.Limit > types.MaxPageSize:
+		query.Limit = types.MaxPageSize
+	}
+
+	switch query.Sort {
+	case "":
+		query.Sort = types.SortDesc
+	case types.SortAsc, types.SortDesc:
+	default:
+		return nil, fmt.Errorf("%w: sort must be %q or %q", types.ErrInvalidQuery, types.SortAsc, types.SortDesc)
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, fmt.Errorf("%w: from must be before to", types.ErrInvalidQuery)
+	}
+
+	return s.repo.FindPage(ctx, query)
+}
+
+func (s *LogService) SearchLogs(ctx context.Context, query types.SearchQuery) (*types.SearchResult, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return nil, fmt.Errorf("%w: search text is required", types.ErrInvalidQuery)
+	}
+
+	if query.Level != "" {
+		level, err := normalizeLevel(query.Level)
+		if err != nil {
+			return nil, err
+		}
+		query.Level = level
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = defaultSearchLimit
+	case query.Limit < 0:
+		return nil, fmt.Errorf("%w: limit must be positive", types.ErrInvalidQuery)
+	case query.Limit > maxSearchLimit:
+		query.Limit = maxSearchLimit
+	}
+
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", types.ErrInvalidQuery)
+	}
+
+	result, err := s.repo.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	h := newHighlighter(query.Text)
+	for i := range result.Hits {
+		hit := &result.Hits[i]
+		highlights := map[string][]string{}
+
+		if snippets := h.snippets(hit.Name); len(snippets) > 0 {
+			highlights["name"] = snippets
+		}
+		if snippets := h.snippets(types.FlattenText(hit.Data)); len(snippets) > 0 {
+			highlights["data"] = snippets
+		}
+
+		if len(highlights) > 0 {
+			hit.Highlights = highlights
+		}
+	}
+
+	return result, nil
 }
 
 func (s *LogService) GetLogByID(ctx context.Context, id string) (*types.Log, error) {
 	if id == "" {
-		return nil, errors.New("log ID is required")
+		return nil, types.ErrLogIDRequired
 	}
 	
 	log, err := s.repo.FindByID(ctx, id)
@@ -31,55 +126,194 @@ func (s *LogService) GetLogByID(ctx context.Context, id string) (*types.Log, err
 		return nil, err
 	}
 	
-	if log == nil {
-		return nil, errors.New("log not found")
+	if log == nil || log.DeletedAt != nil {
+		return nil, types.ErrLogNotFound
 	}
 	
 	return log, nil
 }
 
 func (s *LogService) CreateLog(ctx context.Context, req types.CreateLogRequest) (*types.Log, error) {
+	log, err := newLog(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, log); err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+func (s *LogService) CreateLogs(ctx context.Context, reqs []types.CreateLogRequest) (*types.BulkResult, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: no log entries given", types.ErrInvalidQuery)
+	}
+	if len(reqs) > types.MaxBulkSize {
+		return nil, fmt.Errorf("%w: got %d, the limit is %d", types.ErrBulkTooLarge, len(reqs), types.MaxBulkSize)
+	}
+
+	result := &types.BulkResult{
+		Items: make([]types.BulkItemResult, len(reqs)),
+	}
+
+	logs := make([]*types.Log, 0, len(reqs))
+	positions := make([]int, 0, len(reqs))
+
+	for i, req := range reqs {
+		result.Items[i].Index = i
+
+		log, err := newLog(req)
+		if err != nil {
+			result.Items[i].Status = types.BulkInvalid
+			result.Items[i].Error = err.Error()
+			result.Invalid++
+			continue
+		}
+
+		logs = append(logs, log)
+		positions = append(positions, i)
+	}
+
+	if len(logs) == 0 {
+		return result, nil
+	}
+
+	errs, err := s.repo.CreateLogs(ctx, logs)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, log := range logs {
+		item := &result.Items[positions[j]]
+		if errs[j] != nil {
+			item.Status = types.BulkFailed
+			item.Error = errs[j].Error()
+			result.Failed++
+			continue
+		}
+
+		item.Status = types.BulkCreated
+		item.ID = log.ID
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// newLog validates a create request and builds the entry to store.
+func newLog(req types.CreateLogRequest) (*types.Log, error) {
 	if req.Name == "" {
-		return nil, errors.New("name field is required")
+		return nil, fmt.Errorf("%w: name field is required", types.ErrInvalidLog)
 	}
-	if req.Data == nil {
-		return nil, errors.New("data field is required")
+	if req.Data == nil && len(req.Attributes) == 0 {
+		return nil, fmt.Errorf("%w: data field is required", types.ErrInvalidLog)
+	}
+
+	level, err := normalizeLevel(req.Level)
+	if err != nil {
+		return nil, err
 	}
 
 	log := &types.Log{
-		Name:      req.Name,
-		Data:      req.Data,
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+		Name:       req.Name,
+		Data:       req.Data,
+		Level:      level,
+		Service:    strings.TrimSpace(req.Service),
+		Host:       strings.TrimSpace(req.Host),
+		RequestID:  strings.TrimSpace(req.RequestID),
+		TraceID:    strings.TrimSpace(req.TraceID),
+		Tags:       req.Tags,
+		Attributes: req.Attributes,
+		CreatedAt:  time.Now().UTC(),
+		UpdatedAt:  time.Now().UTC(),
 	}
 
-	if err := s.repo.Create(ctx, log); err != nil {
+	if err := validateStructure(log); err != nil {
 		return nil, err
 	}
 
 	return log, nil
 }
 
+// validateStructure checks the structured fields and normalizes tags to a
+// sorted set so they compare and index consistently.
+func validateStructure(log *types.Log) error {
+	fields := map[string]string{
+		"service":    log.Service,
+		"host":       log.Host,
+		"request_id": log.RequestID,
+		"trace_id":   log.TraceID,
+	}
+	for field, value := range fields {
+		if len(value) > types.MaxFieldLength {
+			return fmt.Errorf("%w: %s is longer than %d characters", types.ErrInvalidLog, field, types.MaxFieldLength)
+		}
+	}
+
+	if len(log.Tags) > types.MaxTags {
+		return fmt.Errorf("%w: at most %d tags are allowed", types.ErrInvalidLog, types.MaxTags)
+	}
+	tags := make([]string, 0, len(log.Tags))
+	for _, tag := range log.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > types.MaxFieldLength {
+			return fmt.Errorf("%w: tags must be between 1 and %d characters", types.ErrInvalidLog, types.MaxFieldLength)
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	log.Tags = nil
+	if len(tags) > 0 {
+		log.Tags = tags
+	}
+
+	if len(log.Attributes) > types.MaxAttributes {
+		return fmt.Errorf("%w: at most %d attributes are allowed", types.ErrInvalidLog, types.MaxAttributes)
+	}
+	for key, value := range log.Attributes {
+		// Keys become BSON field names, where dots and a leading $ are
+		// reserved.
+		if key == "" || len(key) > types.MaxFieldLength || strings.ContainsRune(key, '.') || strings.HasPrefix(key, "$") {
+			return fmt.Errorf("%w: attribute name %q must be non-empty and contain no '.' or leading '$'", types.ErrInvalidLog, key)
+		}
+
+		switch v := value.(type) {
+		case string, bool, int32, int64, float64:
+		case int:
+			log.Attributes[key] = int64(v)
+		default:
+			return fmt.Errorf("%w: attribute %q must be a string, number or bool", types.ErrInvalidLog, key)
+		}
+	}
+	if len(log.Attributes) == 0 {
+		log.Attributes = nil
+	}
+
+	return nil
+}
+
 func (s *LogService) UpdateLog(ctx context.Context, id string, req types.UpdateLogRequest) (*types.Log, error) {
 	if id == "" {
-		return nil, errors.New("log ID is required")
+		return nil, types.ErrLogIDRequired
+	}
+	if req.ChangedBy == "" {
+		return nil, types.ErrActorRequired
 	}
 
 	existingLog, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if existingLog == nil {
-		return nil, errors.New("log not found")
+	if existingLog == nil || existingLog.DeletedAt != nil {
+		return nil, types.ErrLogNotFound
 	}
 
-	updatedLog := &types.Log{
-		ID:        existingLog.ID,
-		Name:      existingLog.Name,
-		Data:      existingLog.Data,
-		CreatedAt: existingLog.CreatedAt,
-		UpdatedAt: time.Now().UTC(),
-	}
+	updatedLog := *existingLog
+	updatedLog.Tags = slices.Clone(existingLog.Tags)
 
 	if req.Name != "" {
 		updatedLog.Name = req.Name
@@ -87,34 +321,379 @@ func (s *LogService) UpdateLog(ctx context.Context, id string, req types.UpdateL
 	if req.Data != nil {
 		updatedLog.Data = req.Data
 	}
+	if req.Level != "" {
+		level, err := normalizeLevel(req.Level)
+		if err != nil {
+			return nil, err
+		}
+		updatedLog.Level = level
+	}
+	if req.Tags != nil {
+		updatedLog.Tags = req.Tags
+	}
+	if req.Attributes != nil {
+		updatedLog.Attributes = req.Attributes
+	}
+	if err := validateStructure(&updatedLog); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, existingLog, &updatedLog, req.ChangedBy)
+}
+
+// update stores updated in place of existing and records existing as a
+// version in the history. An update that changes nothing is not stored.
+func (s *LogService) update(ctx context.Context, existing, updated *types.Log, changedBy string) (*types.Log, error) {
+	diff, err := diffLogs(existing, updated)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", types.ErrInvalidLog, err)
+	}
+	if len(diff) == 0 {
+		return existing, nil
+	}
+
+	updated.Version = existing.CurrentVersion() + 1
+	if err := s.repo.Update(ctx, existing.ID, updated); err != nil {
+		return nil, err
+	}
+
+	// Only the update that moved the entry past this version gets here,
+	// so recording it after the fact cannot race another update.
+	version := &types.LogVersion{
+		LogID:      existing.ID,
+		Version:    existing.CurrentVersion(),
+		Name:       existing.Name,
+		Data:       existing.Data,
+		Level:      existing.Level,
+		Tags:       existing.Tags,
+		Attributes: existing.Attributes,
+		UpdatedAt:  existing.UpdatedAt,
+		ChangedAt:  updated.UpdatedAt,
+		ChangedBy:  changedBy,
+		Diff:       diff,
+	}
+	if err := s.history.Add(ctx, version); err != nil {
+		return nil, fmt.Errorf("log updated but its history could not be recorded: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetLogHistory also serves entries in the trash. Their history stays until
+// the purger deletes them for good, and is removed with them.
+func (s *LogService) GetLogHistory(ctx context.Context, id string) (*types.LogHistory, error) {
+	if id == "" {
+		return nil, types.ErrLogIDRequired
+	}
+
+	log, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if log == nil {
+		return nil, types.ErrLogNotFound
+	}
+
+	versions, err := s.history.FindVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.LogHistory{
+		LogID:          id,
+		CurrentVersion: log.CurrentVersion(),
+		Versions:       versions,
+	}, nil
+}
+
+func (s *LogService) RevertLog(ctx context.Context, id string, version int, changedBy string) (*types.Log, error) {
+	if id == "" {
+		return nil, types.ErrLogIDRequired
+	}
+	if changedBy == "" {
+		return nil, types.ErrActorRequired
+	}
 
-	if err := s.repo.Update(ctx, id, updatedLog); err != nil {
+	existingLog, err := s.repo.FindByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	if existingLog == nil || existingLog.DeletedAt != nil {
+		return nil, types.ErrLogNotFound
+	}
+
+	current := existingLog.CurrentVersion()
+	if current == 1 {
+		return nil, fmt.Errorf("%w: log has no earlier versions", types.ErrInvalidQuery)
+	}
+	if version < 1 || version >= current {
+		return nil, fmt.Errorf("%w: version must be an earlier version, 1 to %d", types.ErrInvalidQuery, current-1)
+	}
+
+	target, err := s.history.FindVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: %d", types.ErrLogVersionNotFound, version)
+	}
+
+	revertedLog := *existingLog
+	revertedLog.Name = target.Name
+	revertedLog.Data = target.Data
+	revertedLog.Level = target.Level
+	revertedLog.Tags = slices.Clone(target.Tags)
+	revertedLog.Attributes = target.Attributes
 
-	return updatedLog, nil
+	return s.update(ctx, existingLog, &revertedLog, changedBy)
 }
 
 func (s *LogService) DeleteLog(ctx context.Context, id string) error {
 	if id == "" {
-		return errors.New("log ID is required")
+		return types.ErrLogIDRequired
 	}
 
 	existingLog, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	if existingLog == nil {
-		return errors.New("log not found")
+	if existingLog == nil || existingLog.DeletedAt != nil {
+		return types.ErrLogNotFound
 	}
 
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *LogService) DropAllLogs(ctx context.Context) error {
-	return s.repo.DropCollection(ctx)
+// DropAllLogs trashes every live entry under a new snapshot ID rather than
+// dropping the collection, so a mistaken drop can be undone until the trash
+// is purged.
+func (s *LogService) DropAllLogs(ctx context.Context) (*types.TrashSnapshot, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	snapshot := &types.TrashSnapshot{
+		ID:        "drop-" + now.Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix),
+		DeletedAt: now,
+	}
+
+	count, err := s.repo.Trash(ctx, types.LogFilter{}, snapshot.ID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Count = count
+
+	return snapshot, nil
+}
+
+// GetTrash pages through deleted entries with the same filters as GetLogs.
+func (s *LogService) GetTrash(ctx context.Context, query types.LogQuery) (*types.LogPage, error) {
+	query.Trash = true
+	return s.GetLogs(ctx, query)
+}
+
+func (s *LogService) RestoreLog(ctx context.Context, id string) (*types.Log, error) {
+	if id == "" {
+		return nil, types.ErrLogIDRequired
+	}
+
+	existingLog, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existingLog == nil || existingLog.DeletedAt == nil {
+		return nil, fmt.Errorf("%w in trash", types.ErrLogNotFound)
+	}
+
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	existingLog.DeletedAt = nil
+	existingLog.Snapshot = ""
+	return existingLog, nil
+}
+
+func (s *LogService) RestoreSnapshot(ctx context.Context, snapshot string) (int64, error) {
+	if snapshot == "" {
+		return 0, fmt.Errorf("%w: snapshot is required", types.ErrInvalidQuery)
+	}
+
+	return s.repo.RestoreMany(ctx, types.LogFilter{Snapshot: snapshot})
+}
+
+func (s *LogService) GetLogStats(ctx context.Context, filter types.LogFilter) (*types.LogStats, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetStats(ctx, filter)
 }
 
-func (s *LogService) GetLogStats(ctx context.Context) (*types.LogStats, error) {
-	return s.repo.GetStats(ctx)
-}
\ No newline at end of file
+func (s *LogService) TailLogs(ctx context.Context, query types.TailQuery, fn func(types.Log) error) error {
+	filter, err := normalizeFilter(query.LogFilter)
+	if err != nil {
+		return err
+	}
+	query.LogFilter = filter
+
+	return s.repo.Tail(ctx, query, fn)
+}
+
+func (s *LogService) ExportLogs(ctx context.Context, filter types.LogFilter, fn func(types.Log) error) error {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Stream(ctx, filter, fn)
+}
+
+// ImportLogs validates each entry and stores the valid ones. The Line of
+// each reported error is the entry's 1-based position in logs.
+func (s *LogService) ImportLogs(ctx context.Context, logs []types.Log) (*types.ImportResult, error) {
+	result := &types.ImportResult{}
+	valid := make([]types.Log, 0, len(logs))
+
+	for i, log := range logs {
+		if err := prepareImport(&log); err != nil {
+			result.Add(&types.ImportResult{
+				Invalid: 1,
+				Errors:  []types.ImportError{{Line: i + 1, Error: err.Error()}},
+			})
+			continue
+		}
+		valid = append(valid, log)
+	}
+
+	imported, skipped, err := s.repo.Import(ctx, valid)
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = imported
+	result.Skipped = skipped
+
+	return result, nil
+}
+
+// prepareImport checks an exported entry and fills in what older exports
+// may lack.
+func prepareImport(log *types.Log) error {
+	if log.Name == "" {
+		return fmt.Errorf("%w: name field is required", types.ErrInvalidLog)
+	}
+	if log.ID != "" && !isObjectID(log.ID) {
+		return fmt.Errorf("%w %q", types.ErrInvalidLogID, log.ID)
+	}
+
+	level, err := normalizeLevel(log.Level)
+	if err != nil {
+		return err
+	}
+	log.Level = level
+
+	if err := validateStructure(log); err != nil {
+		return err
+	}
+
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now().UTC()
+	}
+	if log.UpdatedAt.IsZero() {
+		log.UpdatedAt = log.CreatedAt
+	}
+
+	return nil
+}
+
+func isObjectID(id string) bool {
+	_, err := hex.DecodeString(id)
+	return err == nil && len(id) == 24
+}
+
+// maxHistogramBuckets keeps a single request from asking Mongo to densify
+// an unbounded range.
+const maxHistogramBuckets = 10000
+
+func (s *LogService) GetLogHistogram(ctx context.Context, query types.HistogramQuery) (*types.Histogram, error) {
+	filter, err := normalizeFilter(query.LogFilter)
+	if err != nil {
+		return nil, err
+	}
+	query.LogFilter = filter
+
+	if query.Bucket == "" {
+		query.Bucket = types.BucketHour
+	}
+	size := query.Bucket.Duration()
+	if size == 0 {
+		return nil, fmt.Errorf("%w: bucket must be %s, %s or %s", types.ErrInvalidQuery, types.BucketMinute, types.BucketHour, types.BucketDay)
+	}
+
+	if query.To.IsZero() {
+		query.To = time.Now().UTC()
+	}
+	if query.From.IsZero() {
+		query.From = query.To.Add(-defaultHistogramBuckets * size)
+	}
+	// Buckets are aligned to UTC, and truncating against the zero time is
+	// UTC aligned for minutes, hours and days alike.
+	query.From = query.From.UTC().Truncate(size)
+	query.To = query.To.UTC()
+
+	if !query.From.Before(query.To) {
+		return nil, fmt.Errorf("%w: from must be before to", types.ErrInvalidQuery)
+	}
+	if query.To.Sub(query.From)/size > maxHistogramBuckets {
+		return nil, fmt.Errorf("%w: range covers more than %d %s buckets", types.ErrInvalidQuery, maxHistogramBuckets, query.Bucket)
+	}
+
+	buckets, err := s.repo.Histogram(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Histogram{
+		Bucket:  query.Bucket,
+		From:    query.From,
+		To:      query.To,
+		Buckets: buckets,
+	}, nil
+}
+
+// normalizeFilter validates the level and time range shared by every
+// filtered query.
+func normalizeFilter(filter types.LogFilter) (types.LogFilter, error) {
+	if filter.Level != "" {
+		level, err := normalizeLevel(filter.Level)
+		if err != nil {
+			return filter, err
+		}
+		filter.Level = level
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("%w: from must be before to", types.ErrInvalidQuery)
+	}
+
+	return filter, nil
+}
+
+// normalizeLevel upper-cases level and defaults it to INFO.
+func normalizeLevel(level string) (string, error) {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	if level == "" {
+		return types.DefaultLogLevel, nil
+	}
+
+	for _, valid := range types.LogLevels {
+		if level == valid {
+			return level, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w '%s', valid levels: %s", types.ErrInvalidLogLevel, level, strings.Join(types.LogLevels, ", "))
+}
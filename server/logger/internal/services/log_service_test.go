@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"logger/internal/repositories"
+	"logger/types"
+)
+
+func TestUpdatesRequireAnActor(t *testing.T) {
+	ctx := context.Background()
+	history := repositories.NewMemoryHistoryRepository()
+	service := NewLogService(repositories.NewMemoryRepository(), history)
+
+	created, err := service.CreateLog(ctx, types.CreateLogRequest{Name: "v1", Data: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.UpdateLog(ctx, created.ID, types.UpdateLogRequest{Name: "v2"}); !errors.Is(err, types.ErrActorRequired) {
+		t.Fatalf("UpdateLog without an actor = %v, want ErrActorRequired", err)
+	}
+	if _, err := service.RevertLog(ctx, created.ID, 1, ""); !errors.Is(err, types.ErrActorRequired) {
+		t.Fatalf("RevertLog without an actor = %v, want ErrActorRequired", err)
+	}
+
+	if _, err := service.UpdateLog(ctx, created.ID, types.UpdateLogRequest{Name: "v2", ChangedBy: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	versions, err := history.FindVersions(ctx, created.ID)
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("FindVersions = %d versions, %v; want 1", len(versions), err)
+	}
+	if versions[0].ChangedBy != "ada" {
+		t.Errorf("version changed by %q, want the X-Actor value", versions[0].ChangedBy)
+	}
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"logger/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+type RetentionOptions struct {
+	// Interval is how often the purger runs.
+	Interval time.Duration
+	// ArchiveDir, when set, receives a gzip'd NDJSON file of every entry
+	// before it is deleted.
+	ArchiveDir string
+	// TrashGrace is how long deleted entries stay in the trash; zero keeps
+	// them until deleted some other way.
+	TrashGrace time.Duration
+}
+
+// Retention deletes entries that have outlived their rule. Name rules take
+// precedence over level rules, and level rules over the default, so an
+// entry is only ever governed by one rule. Rules apply to live entries;
+// trashed ones are removed once they outlast the trash grace period.
+//
+// A scheduled purger is used rather than TTL indexes because a TTL index
+// can neither archive what it removes nor apply different ages by name.
+//
+// An entry's history is deleted with it, since old versions hold the same
+// data the retention rule is meant to remove.
+type Retention struct {
+	repo    types.LogRepositoryInterface
+	history types.HistoryRepositoryInterface
+	rules   []types.RetentionRule
+	opts    RetentionOptions
+}
+
+func NewRetention(repo types.LogRepositoryInterface, history types.HistoryRepositoryInterface, rules []types.RetentionRule, opts RetentionOptions) *Retention {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+
+	return &Retention{
+		repo:    repo,
+		history: history,
+		rules:   rules,
+		opts:    opts,
+	}
+}
+
+// Run purges once at start and then every interval until ctx ends.
+func (r *Retention) Run(ctx context.Context) {
+	if len(r.rules) == 0 && r.opts.TrashGrace <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		results, err := r.Purge(ctx)
+		for _, result := range results {
+			if result.Deleted > 0 {
+				log.Printf("Retention %s: deleted %d logs before %s", result.Rule, result.Deleted, result.Cutoff.Format(time.RFC3339))
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Retention purge failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge applies every rule once. It stops at the first failing rule so an
+// archive that could not be written never has its entries deleted.
+func (r *Retention) Purge(ctx context.Context) ([]types.RetentionResult, error) {
+	now := time.Now().UTC()
+	results := []types.RetentionResult{}
+
+	for _, rule := range r.rules {
+		filter := r.filterFor(rule)
+		filter.To = now.Add(-rule.MaxAge)
+
+		result := types.RetentionResult{
+			Rule:   rule.String(),
+			Cutoff: filter.To,
+		}
+
+		if err := r.expire(ctx, rule.String(), filter, now, &result); err != nil {
+			return results, fmt.Errorf("retention %s: %w", rule, err)
+		}
+
+		results = append(results, result)
+	}
+
+	if r.opts.TrashGrace > 0 {
+		filter := types.LogFilter{
+			Trash:         true,
+			DeletedBefore: now.Add(-r.opts.TrashGrace),
+		}
+		result := types.RetentionResult{
+			Rule:   "trash",
+			Cutoff: filter.DeletedBefore,
+		}
+
+		if err := r.expire(ctx, result.Rule, filter, now, &result); err != nil {
+			return results, fmt.Errorf("retention trash: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// deleteBatch bounds the ids sent in one delete.
+const deleteBatch = 1000
+
+// expire archives, when configured, and then deletes the entries matching
+// filter, recording both in result.
+//
+// Only the ids read first are deleted, so the history of exactly those
+// entries can go with them. An entry imported with an old timestamp, or
+// edited into the rule, between the two steps is left for the next run
+// rather than deleted without a copy.
+func (r *Retention) expire(ctx context.Context, name string, filter types.LogFilter, now time.Time, result *types.RetentionResult) error {
+	var ids []string
+	if r.opts.ArchiveDir == "" {
+		err := r.repo.Stream(ctx, filter, func(entry types.Log) error {
+			ids = append(ids, entry.ID)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		path, archived, err := r.archive(ctx, name, filter, now)
+		if err != nil {
+			return err
+		}
+		result.Archive = path
+		result.Archived = int64(len(archived))
+		ids = archived
+	}
+
+	// The filter still applies, so an entry restored or edited out of the
+	// rule since it was read is kept, along with its history.
+	for batch := range slices.Chunk(ids, deleteBatch) {
+		filter.IDs = batch
+		deleted, err := r.repo.DeleteMany(ctx, filter)
+		result.Deleted += deleted
+		if err != nil {
+			return err
+		}
+
+		gone, err := r.gone(ctx, batch)
+		if err != nil {
+			return err
+		}
+		if err := r.history.DeleteFor(ctx, gone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gone returns the ids in batch that are neither live nor in the trash.
+func (r *Retention) gone(ctx context.Context, batch []string) ([]string, error) {
+	kept := map[string]bool{}
+	for _, trash := range []bool{false, true} {
+		found, err := r.repo.FindAll(ctx, types.LogFilter{IDs: batch, Trash: trash})
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range found {
+			kept[entry.ID] = true
+		}
+	}
+
+	var gone []string
+	for _, id := range batch {
+		if !kept[id] {
+			gone = append(gone, id)
+		}
+	}
+	return gone, nil
+}
+
+// filterFor selects the entries a rule governs, leaving out those claimed
+// by a more specific rule.
+func (r *Retention) filterFor(rule types.RetentionRule) types.LogFilter {
+	var filter types.LogFilter
+
+	switch {
+	case rule.Name != "":
+		filter.Names = []string{rule.Name}
+		return filter
+	case rule.Level != "":
+		filter.Level = rule.Level
+	}
+
+	for _, other := range r.rules {
+		switch {
+		case other.Name != "":
+			filter.ExcludeNames = append(filter.ExcludeNames, other.Name)
+		case other.Level != "" && rule.Level == "":
+			filter.ExcludeLevels = append(filter.ExcludeLevels, other.Level)
+		}
+	}
+
+	return filter
+}
+
+// archive writes the expiring entries to one NDJSON file, named after the
+// rule, and syncs it before returning the ids it wrote. Nothing is written
+// when no entries match.
+func (r *Retention) archive(ctx context.Context, name string, filter types.LogFilter, now time.Time) (string, []string, error) {
+	if err := os.MkdirAll(r.opts.ArchiveDir, 0o755); err != nil {
+		return "", nil, err
+	}
+
+	path := filepath.Join(r.opts.ArchiveDir, fmt.Sprintf("%s-%s.ndjson.gz", name, now.Format("20060102T150405Z")))
+	partial := path + ".partial"
+
+	file, err := os.Create(partial)
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(partial)
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	gz := gzip.NewWriter(buffered)
+	encoder := json.NewEncoder(gz)
+
+	var ids []string
+	err = r.repo.Stream(ctx, filter, func(entry types.Log) error {
+		ids = append(ids, entry.ID)
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(ids) == 0 {
+		return "", nil, nil
+	}
+
+	if err := gz.Close(); err != nil {
+		return "", nil, err
+	}
+	if err := buffered.Flush(); err != nil {
+		return "", nil, err
+	}
+	if err := file.Sync(); err != nil {
+		return "", nil, err
+	}
+	if err := file.Close(); err != nil {
+		return "", nil, err
+	}
+
+	// Renaming only once the file is complete means a crash never leaves
+	// an archive that looks whole but is missing entries.
+	if err := os.Rename(partial, path); err != nil {
+		return "", nil, err
+	}
+
+	return path, ids, nil
+}
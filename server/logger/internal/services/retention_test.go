@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"logger/internal/repositories"
+	"logger/types"
+)
+
+// lateImport imports an expired entry as soon as the purger has streamed
+// the archive, as a concurrent restore from backup would.
+type lateImport struct {
+	types.LogRepositoryInterface
+	late types.Log
+	done bool
+}
+
+func (r *lateImport) Stream(ctx context.Context, filter types.LogFilter, fn func(types.Log) error) error {
+	if err := r.LogRepositoryInterface.Stream(ctx, filter, fn); err != nil {
+		return err
+	}
+	if !r.done {
+		r.done = true
+		if _, _, err := r.Import(ctx, []types.Log{r.late}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRetentionDeletesOnlyArchivedEntries(t *testing.T) {
+	ctx := context.Background()
+	old := time.Now().UTC().Add(-48 * time.Hour)
+
+	repo := &lateImport{
+		LogRepositoryInterface: repositories.NewMemoryRepository(),
+		late:                   types.Log{ID: "65e1a0000000000000000002", Name: "late", Level: "DEBUG", Data: "x", CreatedAt: old, UpdatedAt: old},
+	}
+	if _, _, err := repo.Import(ctx, []types.Log{
+		{ID: "65e1a0000000000000000001", Name: "expired", Level: "DEBUG", Data: "x", CreatedAt: old, UpdatedAt: old},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	history := repositories.NewMemoryHistoryRepository()
+	for _, id := range []string{"65e1a0000000000000000001", repo.late.ID} {
+		if err := history.Add(ctx, &types.LogVersion{LogID: id, Version: 1, Name: "old", Level: "DEBUG"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retention := NewRetention(repo, history, []types.RetentionRule{{Level: "DEBUG", MaxAge: time.Hour}}, RetentionOptions{
+		ArchiveDir: t.TempDir(),
+	})
+
+	results, err := retention.Purge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Archived != 1 || results[0].Deleted != 1 {
+		t.Errorf("archived %d and deleted %d, want 1 and 1", results[0].Archived, results[0].Deleted)
+	}
+	if found, err := repo.FindByID(ctx, repo.late.ID); err != nil || found == nil {
+		t.Fatalf("late entry was deleted without an archive: %v", err)
+	}
+	if versions, _ := history.FindVersions(ctx, "65e1a0000000000000000001"); len(versions) != 0 {
+		t.Errorf("purged entry kept %d versions", len(versions))
+	}
+	if versions, _ := history.FindVersions(ctx, repo.late.ID); len(versions) != 1 {
+		t.Errorf("late entry has %d versions, want its history kept", len(versions))
+	}
+
+	results, err = retention.Purge(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Archived != 1 || results[0].Deleted != 1 {
+		t.Errorf("next run archived %d and deleted %d, want 1 and 1", results[0].Archived, results[0].Deleted)
+	}
+	if versions, _ := history.FindVersions(ctx, repo.late.ID); len(versions) != 0 {
+		t.Errorf("purged late entry kept %d versions", len(versions))
+	}
+}
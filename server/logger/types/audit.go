@@ -0,0 +1,90 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry is one record of the tamper-evident audit trail. Entries are
+// numbered from 1 without gaps, and Hash covers the entry's content and
+// PrevHash, the hash of the entry before it. Changing or removing an entry
+// therefore breaks the chain at that point.
+type AuditEntry struct {
+	Seq        int64                  `json:"seq" bson:"_id"`
+	Name       string                 `json:"name" bson:"name"`
+	Data       interface{}            `json:"data,omitempty" bson:"data,omitempty"`
+	Level      string                 `json:"level" bson:"level"`
+	Service    string                 `json:"service,omitempty" bson:"service,omitempty"`
+	Host       string                 `json:"host,omitempty" bson:"host,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty" bson:"trace_id,omitempty"`
+	Tags       []string               `json:"tags,omitempty" bson:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty" bson:"attributes,omitempty"`
+	// CreatedAt has millisecond precision, which is what MongoDB stores,
+	// so the hash survives a round trip through the database.
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	PrevHash  string    `json:"prev_hash" bson:"prev_hash"`
+	Hash      string    `json:"hash" bson:"hash"`
+}
+
+// ComputeHash returns the hex SHA-256 of the entry's canonical JSON, every
+// field but Hash itself. The content is re-encoded through generic JSON
+// values first, so object key order and number types, which differ between
+// storage backends, do not change the result.
+func (e *AuditEntry) ComputeHash() (string, error) {
+	content := *e
+	content.Hash = ""
+	content.CreatedAt = e.CreatedAt.UTC()
+
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(encoded, &generic); err != nil {
+		return "", err
+	}
+
+	canonical, err := json.Marshal(generic)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// AuditQuery pages through the trail in order. After is the Seq of the last
+// entry already seen, 0 for the start.
+type AuditQuery struct {
+	After int64
+	Limit int
+}
+
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	// NextAfter is the After for the next page when HasMore is set.
+	NextAfter int64 `json:"next_after,omitempty"`
+	HasMore   bool  `json:"has_more"`
+	Limit     int   `json:"limit"`
+}
+
+// AuditVerification is the outcome of walking the chain. Checked entries
+// from the start are intact; Break is the first that is not. Truncating
+// the end of the trail leaves a valid chain, so callers that need to detect
+// it should record HeadSeq and HeadHash and compare them on the next run.
+type AuditVerification struct {
+	Valid    bool        `json:"valid"`
+	Checked  int64       `json:"checked"`
+	HeadSeq  int64       `json:"head_seq"`
+	HeadHash string      `json:"head_hash,omitempty"`
+	Break    *AuditBreak `json:"break,omitempty"`
+}
+
+type AuditBreak struct {
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,60 @@
+package types
+
+import "errors"
+
+// Domain errors. Services and repositories return these, wrapped with
+// detail where useful, and handlers map them to HTTP responses with
+// errors.Is, never by comparing messages.
+var (
+	// ErrLogNotFound means no live entry has the ID; trashed entries are
+	// not found except by the trash operations.
+	ErrLogNotFound     = errors.New("log not found")
+	ErrLogIDRequired   = errors.New("log ID is required")
+	ErrInvalidLogID    = errors.New("invalid log ID")
+	ErrInvalidLogLevel = errors.New("invalid log level")
+	ErrInvalidQuery    = errors.New("invalid query")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidBody     = errors.New("invalid request body")
+	ErrBulkTooLarge    = errors.New("too many log entries in one request")
+	ErrInvalidLog      = errors.New("invalid log entry")
+	// ErrConfirmationRequired guards destructive operations the caller must
+	// explicitly confirm.
+	ErrConfirmationRequired = errors.New("confirmation required")
+	// ErrActorRequired means an update did not say who made it.
+	ErrActorRequired = errors.New("X-Actor header is required")
+
+	// ErrLogConflict means the entry changed between reading and updating
+	// it.
+	ErrLogConflict        = errors.New("log was modified concurrently")
+	ErrLogVersionNotFound = errors.New("log version not found")
+	ErrSnapshotNotFound   = errors.New("no logs in trash for snapshot")
+
+	ErrAuditNotFound = errors.New("audit entry not found")
+	// ErrAuditConflict means another writer took the entry's sequence
+	// number; the append should be retried on top of the new head.
+	ErrAuditConflict = errors.New("audit sequence number already taken")
+	// ErrAuditImmutable rejects any attempt to change or remove an audit
+	// entry.
+	ErrAuditImmutable = errors.New("audit entries cannot be updated or deleted")
+	// ErrAuditChainBroken reports a verification that found the chain
+	// altered.
+	ErrAuditChainBroken = errors.New("audit chain is broken")
+)
+
+// ProblemTypeBase prefixes a problem's code to form its type URI.
+const ProblemTypeBase = "urn:logger:problem:"
+
+// Problem is an RFC 7807 problem details document, the body of every error
+// response. Code is stable and meant for clients to switch on; Title and
+// Detail are for people and may change.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Data carries what was done before the failure, such as a partial
+	// import or the break found in the audit chain.
+	Data interface{} `json:"data,omitempty"`
+}
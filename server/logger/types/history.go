@@ -0,0 +1,47 @@
+package types
+
+import "time"
+
+// CurrentVersion is the entry's version number, 1 until its first update.
+// Entries written before versions were tracked have none and are at 1.
+func (l *Log) CurrentVersion() int {
+	if l.Version == 0 {
+		return 1
+	}
+	return l.Version
+}
+
+// LogVersion is an earlier state of an entry, recorded when an update
+// replaced it. It holds the fields an update can change.
+type LogVersion struct {
+	LogID      string                 `json:"log_id" bson:"log_id"`
+	Version    int                    `json:"version" bson:"version"`
+	Name       string                 `json:"name" bson:"name"`
+	Data       interface{}            `json:"data" bson:"data"`
+	Level      string                 `json:"level" bson:"level"`
+	Tags       []string               `json:"tags,omitempty" bson:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty" bson:"attributes,omitempty"`
+	// UpdatedAt is when this version was written.
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	// ChangedAt and ChangedBy describe the update that replaced this
+	// version, and Diff what it changed.
+	ChangedAt time.Time `json:"changed_at" bson:"changed_at"`
+	ChangedBy string    `json:"changed_by" bson:"changed_by"`
+	Diff      []PatchOp `json:"diff" bson:"diff"`
+}
+
+// PatchOp is one RFC 6902 JSON Patch operation. Old, which the RFC does not
+// define, carries the replaced or removed value for reading the history.
+type PatchOp struct {
+	Op    string      `json:"op" bson:"op"`
+	Path  string      `json:"path" bson:"path"`
+	Value interface{} `json:"value,omitempty" bson:"value,omitempty"`
+	Old   interface{} `json:"old,omitempty" bson:"old,omitempty"`
+}
+
+// LogHistory lists an entry's earlier versions, oldest first.
+type LogHistory struct {
+	LogID          string       `json:"log_id"`
+	CurrentVersion int          `json:"current_version"`
+	Versions       []LogVersion `json:"versions"`
+}
@@ -5,21 +5,119 @@ import (
 )
 
 type LogServiceInterface interface {
-	GetAllLogs(ctx context.Context) ([]Log, error)
+	GetLogs(ctx context.Context, query LogQuery) (*LogPage, error)
+	SearchLogs(ctx context.Context, query SearchQuery) (*SearchResult, error)
 	GetLogByID(ctx context.Context, id string) (*Log, error)
 	CreateLog(ctx context.Context, req CreateLogRequest) (*Log, error)
+	// CreateLogs stores every valid entry and reports each one's outcome;
+	// an error means the whole batch could not be attempted.
+	CreateLogs(ctx context.Context, reqs []CreateLogRequest) (*BulkResult, error)
+	// UpdateLog records the version it replaces in the entry's history.
 	UpdateLog(ctx context.Context, id string, req UpdateLogRequest) (*Log, error)
+	GetLogHistory(ctx context.Context, id string) (*LogHistory, error)
+	// RevertLog makes an earlier version current again. The revert is
+	// itself an update, so the history keeps what it replaced.
+	RevertLog(ctx context.Context, id string, version int, changedBy string) (*Log, error)
+	// DeleteLog moves the entry to the trash.
 	DeleteLog(ctx context.Context, id string) error
-	DropAllLogs(ctx context.Context) error
-	GetLogStats(ctx context.Context) (*LogStats, error)
+	// DropAllLogs moves every live entry to the trash as one snapshot.
+	DropAllLogs(ctx context.Context) (*TrashSnapshot, error)
+	GetTrash(ctx context.Context, query LogQuery) (*LogPage, error)
+	RestoreLog(ctx context.Context, id string) (*Log, error)
+	RestoreSnapshot(ctx context.Context, snapshot string) (int64, error)
+	// ExportLogs calls fn for every matching entry, oldest first.
+	ExportLogs(ctx context.Context, filter LogFilter, fn func(Log) error) error
+	// ImportLogs restores exported entries, keeping their IDs and
+	// timestamps.
+	ImportLogs(ctx context.Context, logs []Log) (*ImportResult, error)
+	GetLogStats(ctx context.Context, filter LogFilter) (*LogStats, error)
+	GetLogHistogram(ctx context.Context, query HistogramQuery) (*Histogram, error)
+	// TailLogs blocks, calling fn for each new matching entry, until ctx
+	// ends or fn returns an error.
+	TailLogs(ctx context.Context, query TailQuery, fn func(Log) error) error
 }
 
 type LogRepositoryInterface interface {
-	FindAll(ctx context.Context) ([]Log, error)
+	FindAll(ctx context.Context, filter LogFilter) ([]Log, error)
+	FindPage(ctx context.Context, query LogQuery) (*LogPage, error)
+	// Search returns hits ordered by relevance with Score set; highlighting
+	// is left to the caller.
+	Search(ctx context.Context, query SearchQuery) (*SearchResult, error)
+	// FindByID finds live and trashed entries alike, returning nil when
+	// there is none. Methods taking an ID return ErrInvalidLogID when it is
+	// malformed.
 	FindByID(ctx context.Context, id string) (*Log, error)
+	Tail(ctx context.Context, query TailQuery, fn func(Log) error) error
 	Create(ctx context.Context, log *Log) error
+	// CreateLogs inserts the entries unordered, so one rejected entry does
+	// not stop the rest. It sets the ID of each entry and returns the
+	// per-entry errors, nil where the insert succeeded.
+	CreateLogs(ctx context.Context, logs []*Log) ([]error, error)
+	// Update changes a live entry; entries in the trash are not found and
+	// return ErrLogNotFound, as Delete and Restore do.
+	// log.Version must be one more than the stored version, otherwise
+	// another update got there first and ErrLogConflict is returned.
 	Update(ctx context.Context, id string, log *Log) error
+	// Delete moves a live entry to the trash.
 	Delete(ctx context.Context, id string) error
+	// Trash moves every matching live entry to the trash, tagged with
+	// snapshot, and returns how many were moved.
+	Trash(ctx context.Context, filter LogFilter, snapshot string) (int64, error)
+	// Restore takes an entry out of the trash.
+	Restore(ctx context.Context, id string) error
+	// RestoreMany takes every matching entry out of the trash; the
+	// filter's Trash flag is implied.
+	RestoreMany(ctx context.Context, filter LogFilter) (int64, error)
 	DropCollection(ctx context.Context) error
-	GetStats(ctx context.Context) (*LogStats, error)
-}
\ No newline at end of file
+	GetStats(ctx context.Context, filter LogFilter) (*LogStats, error)
+	// Histogram returns every bucket in the range, including empty ones.
+	Histogram(ctx context.Context, query HistogramQuery) ([]HistogramBucket, error)
+	// Stream calls fn for each matching entry, oldest first, without
+	// loading the result into memory.
+	Stream(ctx context.Context, filter LogFilter, fn func(Log) error) error
+	// DeleteMany removes matching entries permanently.
+	DeleteMany(ctx context.Context, filter LogFilter) (int64, error)
+	// Import inserts entries as given, keeping their IDs. It returns how
+	// many were inserted and how many were skipped as already present.
+	Import(ctx context.Context, logs []Log) (inserted int64, skipped int64, err error)
+	EnsureIndexes(ctx context.Context) error
+}
+type AuditServiceInterface interface {
+	AppendAudit(ctx context.Context, req CreateLogRequest) (*AuditEntry, error)
+	GetAuditEntry(ctx context.Context, seq int64) (*AuditEntry, error)
+	ListAudit(ctx context.Context, query AuditQuery) (*AuditPage, error)
+	// VerifyAudit walks the chain from the first entry and reports the
+	// first one that does not hold.
+	VerifyAudit(ctx context.Context) (*AuditVerification, error)
+}
+
+// AuditRepositoryInterface stores the audit trail. It deliberately has no
+// way to update or delete an entry.
+type AuditRepositoryInterface interface {
+	// Last returns the head of the chain, or nil when the trail is empty.
+	Last(ctx context.Context) (*AuditEntry, error)
+	// Append stores the entry, or returns ErrAuditConflict when its Seq is
+	// already taken.
+	Append(ctx context.Context, entry *AuditEntry) error
+	FindBySeq(ctx context.Context, seq int64) (*AuditEntry, error)
+	// FindAfter returns up to limit entries with Seq greater than after,
+	// in order.
+	FindAfter(ctx context.Context, after int64, limit int) ([]AuditEntry, error)
+	// Walk calls fn for every entry in order without loading the trail
+	// into memory.
+	Walk(ctx context.Context, fn func(AuditEntry) error) error
+}
+
+// HistoryRepositoryInterface stores the earlier versions of log entries.
+type HistoryRepositoryInterface interface {
+	// Add records a version. Versions of one entry are unique.
+	Add(ctx context.Context, version *LogVersion) error
+	// FindVersions returns an entry's versions, oldest first.
+	FindVersions(ctx context.Context, logID string) ([]LogVersion, error)
+	// FindVersion returns nil when the entry has no such version.
+	FindVersion(ctx context.Context, logID string, version int) (*LogVersion, error)
+	// DeleteFor removes every version of the given entries. It is called
+	// once they are deleted for good, so their old content goes with them.
+	DeleteFor(ctx context.Context, logIDs []string) error
+	EnsureIndexes(ctx context.Context) error
+}
@@ -0,0 +1,59 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// FlattenText joins every scalar value in data, including values nested in
+// objects and arrays, into one space separated string. It is what the text
+// index sees for a log's data.
+func FlattenText(data any) string {
+	var parts []string
+	flatten(data, &parts)
+	return strings.Join(parts, " ")
+}
+
+func flatten(data any, parts *[]string) {
+	switch v := data.(type) {
+	case nil:
+	case string:
+		if v != "" {
+			*parts = append(*parts, v)
+		}
+	case bson.D:
+		for _, e := range v {
+			flatten(e.Value, parts)
+		}
+	case bson.M:
+		flattenMap(v, parts)
+	case map[string]any:
+		flattenMap(v, parts)
+	case bson.A:
+		for _, item := range v {
+			flatten(item, parts)
+		}
+	case []any:
+		for _, item := range v {
+			flatten(item, parts)
+		}
+	default:
+		*parts = append(*parts, fmt.Sprint(v))
+	}
+}
+
+func flattenMap(m map[string]any, parts *[]string) {
+	// Sorted keys keep the derived text stable between writes.
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		flatten(m[k], parts)
+	}
+}
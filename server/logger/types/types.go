@@ -5,6 +5,26 @@ import (
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+// LogLevels are the severities a log entry can carry.
+var LogLevels = []string{"DEBUG", "INFO", "WARNING", "ERROR"}
+
+const DefaultLogLevel = "INFO"
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+
+	// MaxBulkSize caps the entries accepted by one bulk request.
+	MaxBulkSize = 1000
+)
+
+type SortDirection string
+
+const (
+	SortDesc SortDirection = "desc"
+	SortAsc  SortDirection = "asc"
+)
+
 type JsonResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
@@ -12,28 +32,299 @@ type JsonResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
+// Log is one entry. Name and Data are all that older producers send; the
+// other fields are optional structure that can be filtered on. Service and
+// Host identify the producer, and Attributes hold scalar values (string,
+// number or bool) stored with their types so queries can compare them.
 type Log struct {
-	ID        string      `json:"id" bson:"_id,omitempty"`
-	Name      string      `json:"name" bson:"name"`
-	Data      interface{} `json:"data" bson:"data"`
-	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time   `json:"updated_at" bson:"updated_at"`
+	ID         string                 `json:"id" bson:"_id,omitempty"`
+	Name       string                 `json:"name" bson:"name"`
+	Data       interface{}            `json:"data" bson:"data"`
+	Level      string                 `json:"level" bson:"level"`
+	Service    string                 `json:"service,omitempty" bson:"service,omitempty"`
+	Host       string                 `json:"host,omitempty" bson:"host,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty" bson:"trace_id,omitempty"`
+	Tags       []string               `json:"tags,omitempty" bson:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty" bson:"attributes,omitempty"`
+	CreatedAt  time.Time              `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at" bson:"updated_at"`
+	// Version counts updates; see CurrentVersion.
+	Version int `json:"version,omitempty" bson:"version,omitempty"`
+	// DeletedAt is set while the entry is in the trash. Snapshot groups
+	// the entries trashed together by DropAllLogs.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	Snapshot  string     `json:"snapshot,omitempty" bson:"snapshot,omitempty"`
+	// DataText is FlattenText(Data), stored so the text index covers
+	// nested fields.
+	DataText string `json:"-" bson:"data_text,omitempty"`
 }
 
 type LogStats struct {
-	TotalLogs    int64     `json:"total_logs"`
-	LastLogTime  time.Time `json:"last_log_time"`
-	FirstLogTime time.Time `json:"first_log_time"`
+	TotalLogs    int64            `json:"total_logs"`
+	LastLogTime  time.Time        `json:"last_log_time"`
+	FirstLogTime time.Time        `json:"first_log_time"`
+	ByLevel      map[string]int64 `json:"by_level"`
+	// ByName holds the most frequent names, most frequent first.
+	ByName []NameCount `json:"by_name"`
+}
+
+type NameCount struct {
+	Name  string `json:"name" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+type BucketSize string
+
+const (
+	BucketMinute BucketSize = "minute"
+	BucketHour   BucketSize = "hour"
+	BucketDay    BucketSize = "day"
+)
+
+// Duration is the length of one bucket.
+func (b BucketSize) Duration() time.Duration {
+	switch b {
+	case BucketMinute:
+		return time.Minute
+	case BucketHour:
+		return time.Hour
+	case BucketDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// HistogramQuery counts logs per bucket over [From, To). From is rounded
+// down to a bucket boundary.
+type HistogramQuery struct {
+	LogFilter
+	Bucket BucketSize
+}
+
+type HistogramBucket struct {
+	Start time.Time `json:"start" bson:"start"`
+	Count int64     `json:"count" bson:"count"`
+}
+
+type Histogram struct {
+	Bucket  BucketSize        `json:"bucket"`
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Buckets []HistogramBucket `json:"buckets"`
 }
 
+// Limits on the structured fields of an entry.
+const (
+	MaxFieldLength = 256
+	MaxTags        = 32
+	MaxAttributes  = 64
+)
+
+// CreateLogRequest accepts the original {name, data} shape as well as the
+// structured fields. Data may be omitted when attributes are given.
 type CreateLogRequest struct {
-	Name string      `json:"name" validate:"required"`
-	Data interface{} `json:"data" validate:"required"`
+	Name       string                 `json:"name" validate:"required"`
+	Data       interface{}            `json:"data"`
+	Level      string                 `json:"level,omitempty"`
+	Service    string                 `json:"service,omitempty"`
+	Host       string                 `json:"host,omitempty"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
+type BulkItemStatus string
+
+const (
+	BulkCreated BulkItemStatus = "created"
+	// BulkInvalid entries were rejected before reaching the database.
+	BulkInvalid BulkItemStatus = "invalid"
+	// BulkFailed entries were valid but the database refused them.
+	BulkFailed BulkItemStatus = "failed"
+)
+
+// BulkItemResult is the outcome of one entry, at its position in the
+// request.
+type BulkItemResult struct {
+	Index  int            `json:"index"`
+	Status BulkItemStatus `json:"status"`
+	ID     string         `json:"id,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+type BulkResult struct {
+	Created int              `json:"created"`
+	Invalid int              `json:"invalid"`
+	Failed  int              `json:"failed"`
+	Items   []BulkItemResult `json:"items"`
+}
+
+// UpdateLogRequest changes the given fields. Tags and Attributes replace
+// the stored values when present; the producer fields cannot be changed.
+// ChangedBy, recorded in the entry's history, comes from the X-Actor
+// header rather than the body. The header is required but not verified:
+// the logger trusts its callers, so it names who the caller says made the
+// change and is advisory rather than an audit of who did.
 type UpdateLogRequest struct {
-	Name string      `json:"name,omitempty"`
-	Data interface{} `json:"data,omitempty"`
+	Name       string                 `json:"name,omitempty"`
+	Data       interface{}            `json:"data,omitempty"`
+	Level      string                 `json:"level,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	ChangedBy  string                 `json:"-"`
+}
+
+// LogFilter narrows log queries; zero values match everything.
+type LogFilter struct {
+	Level string
+	Names []string
+	From  time.Time
+	To    time.Time
+	// Search is a case-insensitive substring match over data.
+	Search    string
+	Service   string
+	RequestID string
+	TraceID   string
+	// Tags matches entries carrying every one of the tags.
+	Tags []string
+	// ExcludeNames and ExcludeLevels drop entries that would otherwise
+	// match.
+	ExcludeNames  []string
+	ExcludeLevels []string
+	// Trash selects entries in the trash instead of live ones, optionally
+	// only those deleted before DeletedBefore or trashed by Snapshot.
+	Trash         bool
+	DeletedBefore time.Time
+	Snapshot      string
+	// IDs, when not nil, limits the filter to those entries.
+	IDs []string
+}
+
+// DefaultTrashGrace is how long deleted entries stay restorable.
+const DefaultTrashGrace = 30 * 24 * time.Hour
+
+// TrashSnapshot identifies the entries DropAllLogs moved to the trash, so
+// they can be restored together.
+type TrashSnapshot struct {
+	ID        string    `json:"id"`
+	Count     int64     `json:"count"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// RetentionRule deletes entries older than MaxAge. A rule selects by Name
+// or by Level; a rule with neither is the default for everything the other
+// rules do not claim.
+type RetentionRule struct {
+	Name   string
+	Level  string
+	MaxAge time.Duration
+}
+
+// String identifies the rule in logs and archive file names.
+func (r RetentionRule) String() string {
+	switch {
+	case r.Name != "":
+		return "name-" + r.Name
+	case r.Level != "":
+		return "level-" + r.Level
+	default:
+		return "default"
+	}
+}
+
+// RetentionResult reports one rule's pass of the purger.
+type RetentionResult struct {
+	Rule     string    `json:"rule"`
+	Cutoff   time.Time `json:"cutoff"`
+	Archived int64     `json:"archived"`
+	Archive  string    `json:"archive,omitempty"`
+	Deleted  int64     `json:"deleted"`
+}
+
+// LogQuery is a filtered page of logs ordered by creation time. Cursor is
+// the NextCursor of the previous page and is empty for the first page.
+type LogQuery struct {
+	LogFilter
+	Cursor string
+	Limit  int
+	Sort   SortDirection
+}
+
+type ExportFormat string
+
+const (
+	ExportNDJSON ExportFormat = "ndjson"
+	ExportCSV    ExportFormat = "csv"
+)
+
+// ImportResult counts the outcome of restoring an export. Entries whose ID
+// already exists are skipped, so an import can safely be repeated.
+type ImportResult struct {
+	Imported int64 `json:"imported"`
+	Skipped  int64 `json:"skipped"`
+	Invalid  int64 `json:"invalid"`
+	// Errors describes the first MaxImportErrors invalid entries.
+	Errors []ImportError `json:"errors,omitempty"`
+}
+
+const MaxImportErrors = 20
+
+// ImportError is an entry that could not be restored. Line counts from 1.
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// Add folds the result of one batch into r.
+func (r *ImportResult) Add(batch *ImportResult) {
+	r.Imported += batch.Imported
+	r.Skipped += batch.Skipped
+	r.Invalid += batch.Invalid
+	for _, e := range batch.Errors {
+		if len(r.Errors) < MaxImportErrors {
+			r.Errors = append(r.Errors, e)
+		}
+	}
+}
+
+// TailQuery follows new entries as they are written. AfterID resumes a
+// tail: entries written after that one are delivered first.
+type TailQuery struct {
+	LogFilter
+	AfterID string
+}
+
+// SearchQuery is a full-text search ranked by relevance. Results are paged
+// by offset because relevance order has no stable key to resume from.
+type SearchQuery struct {
+	LogFilter
+	Text   string
+	Limit  int
+	Offset int
+}
+
+type SearchHit struct {
+	Log
+	Score float64 `json:"score"`
+	// Highlights maps a field to snippets with matched terms wrapped in
+	// <mark> tags.
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+type SearchResult struct {
+	Hits   []SearchHit `json:"hits"`
+	Total  int64       `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
+type LogPage struct {
+	Logs       []Log  `json:"logs"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Limit      int    `json:"limit"`
 }
 
 func (l *Log) MarshalBSON() ([]byte, error) {
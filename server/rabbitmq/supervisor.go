@@ -0,0 +1,275 @@
+// Package rabbitmq supervises the RabbitMQ connection shared by the broker
+// and the listener.
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	ErrNotConnected = errors.New("not connected to RabbitMQ")
+	ErrClosed       = errors.New("rabbitmq supervisor is closed")
+)
+
+type State string
+
+const (
+	StateConnecting State = "connecting"
+	StateConnected  State = "connected"
+	StateClosed     State = "closed"
+)
+
+// Supervisor owns the RabbitMQ connection. It watches NotifyClose and
+// redials with backoff whenever the connection drops, running the
+// registered setup hooks again so exchanges and queues are redeclared.
+type Supervisor struct {
+	url        string
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu          sync.RWMutex
+	conn        *amqp.Connection
+	state       State
+	ready       chan struct{}
+	hooks       []func(*amqp.Connection) error
+	connectedAt time.Time
+	reconnects  int
+	lastError   string
+
+	done chan struct{}
+}
+
+func NewSupervisor(url string) *Supervisor {
+	return &Supervisor{
+		url:        url,
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+		state:      StateConnecting,
+		ready:      make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// OnConnect registers fn to run on every new connection, before it is
+// handed out. It must be called before Start.
+func (s *Supervisor) OnConnect(fn func(*amqp.Connection) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, fn)
+}
+
+// Start makes the first connection, giving up after attempts tries, and
+// then keeps the connection alive in the background until Close.
+func (s *Supervisor) Start(attempts int) error {
+	var err error
+
+	for i := 1; i <= attempts; i++ {
+		var conn *amqp.Connection
+		if conn, err = s.dial(); err == nil {
+			log.Println("Connected to RabbitMQ!")
+			go s.watch(conn)
+			return nil
+		}
+
+		log.Printf("RabbitMQ not yet ready (attempt %d/%d): %v", i, attempts, err)
+		if i < attempts {
+			time.Sleep(s.backoff(i))
+		}
+	}
+
+	return err
+}
+
+// Connection returns the current connection, or ErrNotConnected while a
+// reconnect is in progress.
+func (s *Supervisor) Connection() (*amqp.Connection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	switch s.state {
+	case StateConnected:
+		return s.conn, nil
+	case StateClosed:
+		return nil, ErrClosed
+	default:
+		return nil, ErrNotConnected
+	}
+}
+
+// Wait blocks until a connection is available.
+func (s *Supervisor) Wait(ctx context.Context) (*amqp.Connection, error) {
+	for {
+		s.mu.RLock()
+		state, conn, ready := s.state, s.conn, s.ready
+		s.mu.RUnlock()
+
+		switch state {
+		case StateConnected:
+			return conn, nil
+		case StateClosed:
+			return nil, ErrClosed
+		}
+
+		select {
+		case <-ready:
+		case <-s.done:
+			return nil, ErrClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+type Status struct {
+	State       State      `json:"state"`
+	ConnectedAt *time.Time `json:"connected_at,omitempty"`
+	Reconnects  int        `json:"reconnects"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+func (s *Supervisor) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	status := Status{
+		State:      s.state,
+		Reconnects: s.reconnects,
+		LastError:  s.lastError,
+	}
+
+	if s.state == StateConnected {
+		connectedAt := s.connectedAt
+		status.ConnectedAt = &connectedAt
+	}
+
+	return status
+}
+
+func (s *Supervisor) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == StateClosed {
+		return nil
+	}
+
+	s.state = StateClosed
+	close(s.done)
+
+	if s.conn != nil && !s.conn.IsClosed() {
+		return s.conn.Close()
+	}
+	return nil
+}
+
+// dial connects and runs the setup hooks. Only a fully set up connection
+// is published to callers.
+func (s *Supervisor) dial() (*amqp.Connection, error) {
+	conn, err := amqp.Dial(s.url)
+	if err != nil {
+		s.setError(err)
+		return nil, err
+	}
+
+	s.mu.RLock()
+	hooks := s.hooks
+	s.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(conn); err != nil {
+			conn.Close()
+			s.setError(err)
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == StateClosed {
+		conn.Close()
+		return nil, ErrClosed
+	}
+
+	s.conn = conn
+	s.state = StateConnected
+	s.connectedAt = time.Now()
+	s.lastError = ""
+	close(s.ready)
+
+	return conn, nil
+}
+
+func (s *Supervisor) watch(conn *amqp.Connection) {
+	for {
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-s.done:
+			return
+		case amqpErr := <-closed:
+			if !s.lost(amqpErr) {
+				return
+			}
+		}
+
+		for attempt := 1; ; attempt++ {
+			select {
+			case <-s.done:
+				return
+			case <-time.After(s.backoff(attempt)):
+			}
+
+			var err error
+			if conn, err = s.dial(); err == nil {
+				log.Printf("Reconnected to RabbitMQ after %d attempt(s)", attempt)
+				break
+			}
+			if errors.Is(err, ErrClosed) {
+				return
+			}
+			log.Printf("RabbitMQ reconnect attempt %d failed: %v", attempt, err)
+		}
+	}
+}
+
+// lost marks the connection as gone and reports whether to reconnect.
+func (s *Supervisor) lost(amqpErr *amqp.Error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == StateClosed {
+		return false
+	}
+
+	s.state = StateConnecting
+	s.ready = make(chan struct{})
+	s.reconnects++
+	if amqpErr != nil {
+		s.lastError = amqpErr.Error()
+	} else {
+		s.lastError = "connection closed"
+	}
+
+	log.Printf("RabbitMQ connection lost: %s", s.lastError)
+	return true
+}
+
+func (s *Supervisor) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err.Error()
+}
+
+func (s *Supervisor) backoff(attempt int) time.Duration {
+	delay := s.minBackoff << min(attempt-1, 16)
+	return min(delay, s.maxBackoff)
+}